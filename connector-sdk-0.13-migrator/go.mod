@@ -2,4 +2,12 @@ module github.com/conduitio/tools/connector-sdk-0.13-migrator
 
 go 1.23.2
 
-require github.com/conduitio/yaml/v3 v3.3.0
+require (
+	github.com/conduitio/yaml/v3 v3.3.0
+	golang.org/x/mod v0.22.0
+)
+
+require (
+	golang.org/x/sync v0.10.0 // indirect
+	golang.org/x/tools v0.29.0
+)
@@ -0,0 +1,345 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"maps"
+	"slices"
+)
+
+// UpdateAcceptanceTests migrates the acceptance tests built on
+// sdk.AcceptanceTest. The driver API didn't change in SDK v0.13, but the
+// configuration is parsed from the connector specification, so the connector
+// needs to load its specification from connector.yaml and custom driver hooks
+// can't call Configure and Parameters anymore.
+type UpdateAcceptanceTests struct{}
+
+func (u UpdateAcceptanceTests) Description() string {
+	return "Update the acceptance test drivers"
+}
+
+func (u UpdateAcceptanceTests) Check(fsys FS) (Status, error) {
+	pkgs, err := parsePackages(fsys)
+	if err != nil {
+		return 0, err
+	}
+	files := 0
+	for _, pkg := range pkgs {
+		for _, f := range pkg.tests {
+			if !isAcceptanceTest(f) {
+				continue
+			}
+			files++
+			changes := u.findChanges(pkg, f)
+			if slices.ContainsFunc(changes, func(c acceptanceChange) bool { return c.apply != nil }) {
+				return StatusNeedsMigration, nil
+			}
+		}
+	}
+	if files == 0 {
+		return StatusNotApplicable, nil
+	}
+	return StatusAlreadyApplied, nil
+}
+
+func (u UpdateAcceptanceTests) Migrate(ctx context.Context, fsys FS) error {
+	pkgs, err := parsePackages(fsys)
+	if err != nil {
+		return err
+	}
+	report := StepReportFromContext(ctx)
+
+	edits := newGoEdits()
+	for _, pkg := range pkgs {
+		for _, f := range pkg.tests {
+			if !isAcceptanceTest(f) {
+				continue
+			}
+			for _, change := range u.findChanges(pkg, f) {
+				pos := f.fset.Position(change.pos)
+				if change.apply != nil {
+					change.apply(edits)
+				}
+				if change.warning != "" {
+					report.Warnf("%s: %s", pos, change.warning)
+				} else {
+					report.Notef("%s: %s", pos, change.note)
+				}
+			}
+		}
+	}
+
+	_, err = edits.write(fsys)
+	return err
+}
+
+// isAcceptanceTest returns true if the file uses the acceptance tests of the
+// SDK.
+func isAcceptanceTest(f *goFile) bool {
+	alias, ok := importName(f.ast, sdkModule)
+	if !ok {
+		return false
+	}
+	found := false
+	ast.Inspect(f.ast, func(n ast.Node) bool {
+		if expr, ok := n.(ast.Expr); ok &&
+			(isSelector(expr, alias, "AcceptanceTest") || isSelector(expr, alias, "ConfigurableAcceptanceTestDriver")) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// acceptanceChange is a change of an acceptance test. Changes that can't be
+// made automatically only have a warning, changes that need to be reviewed
+// have both.
+type acceptanceChange struct {
+	pos     token.Pos
+	note    string
+	warning string
+	apply   func(edits *goEdits)
+}
+
+// findChanges returns the changes needed in the acceptance test file, in the
+// order they appear in the file.
+func (u UpdateAcceptanceTests) findChanges(pkg *goPackage, f *goFile) []acceptanceChange {
+	alias, _ := importName(f.ast, sdkModule)
+	hooks := u.driverHooks(f, alias)
+
+	var changes []acceptanceChange
+	ast.Inspect(f.ast, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CompositeLit:
+			if isSelector(n.Type, alias, "Connector") {
+				if change, ok := u.connectorChange(pkg, f, n); ok {
+					changes = append(changes, change)
+				}
+				return false
+			}
+		case *ast.CallExpr:
+			if change, ok := u.callChange(f, n, alias, hooks); ok {
+				changes = append(changes, change)
+			}
+		}
+		return true
+	})
+	return changes
+}
+
+// driverHooks returns the methods overriding the methods of the configurable
+// driver, by the position of their body.
+func (u UpdateAcceptanceTests) driverHooks(f *goFile, alias string) map[*ast.BlockStmt]string {
+	drivers := make(map[string]bool)
+	for _, decl := range f.ast.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			for _, field := range st.Fields.List {
+				typ := field.Type
+				if star, ok := typ.(*ast.StarExpr); ok {
+					typ = star.X
+				}
+				if len(field.Names) == 0 && isSelector(typ, alias, "ConfigurableAcceptanceTestDriver") {
+					drivers[ts.Name.Name] = true
+				}
+			}
+		}
+	}
+
+	hooks := make(map[*ast.BlockStmt]string)
+	for _, decl := range f.ast.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || fn.Body == nil {
+			continue
+		}
+		if name := receiverTypeName(fn.Recv.List[0].Type); drivers[name] {
+			hooks[fn.Body] = name + "." + fn.Name.Name
+		}
+	}
+	return hooks
+}
+
+// connectorChange replaces a connector literal in the package declaring the
+// Connector variable with the variable, so the test uses the specification
+// from connector.yaml. A literal setting other fields than the variable only
+// gets the specification of the variable, since the test might use other
+// constructors on purpose. Elsewhere, a literal not using the specification
+// from connector.yaml is reported.
+func (u UpdateAcceptanceTests) connectorChange(pkg *goPackage, f *goFile, lit *ast.CompositeLit) (acceptanceChange, bool) {
+	alias, _ := importName(f.ast, sdkModule)
+	var spec *ast.KeyValueExpr
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok && isIdent(kv.Key, "NewSpecification") {
+			spec = kv
+		}
+	}
+	if spec != nil {
+		if call, ok := spec.Value.(*ast.CallExpr); ok && isSelector(call.Fun, alias, "YAMLSpecification") {
+			return acceptanceChange{}, false
+		}
+	}
+
+	connector, connectorFile, connectorLit := pkg.connectorDecl()
+	if connector == "" || f.ast.Name.Name != pkg.packageName() {
+		if spec == nil {
+			return acceptanceChange{}, false
+		}
+		return acceptanceChange{
+			pos:     spec.Pos(),
+			warning: fmt.Sprintf("the connector used in the acceptance test needs to load its specification with %s.YAMLSpecification, migrate it manually", alias),
+		}, true
+	}
+
+	if u.sameFields(f, lit, connectorFile, connectorLit) {
+		return acceptanceChange{
+			pos:  lit.Pos(),
+			note: fmt.Sprintf("replaced the connector literal with %s", connector),
+			apply: func(edits *goEdits) {
+				edits.add(f, textEdit{start: f.offset(lit.Pos()), end: f.offset(lit.End()), text: connector})
+			},
+		}, true
+	}
+
+	value := connector + ".NewSpecification"
+	if spec != nil && f.text(spec.Value) == value {
+		return acceptanceChange{}, false
+	}
+	return acceptanceChange{
+		pos:     lit.Pos(),
+		warning: fmt.Sprintf("the connector literal sets other fields than %s, set its NewSpecification to %s, check that the test uses the right connector", connector, value),
+		apply: func(edits *goEdits) {
+			if spec != nil {
+				edits.add(f, textEdit{start: f.offset(spec.Value.Pos()), end: f.offset(spec.Value.End()), text: value})
+				return
+			}
+			// Formatting fixes the indentation.
+			lbrace := f.offset(lit.Lbrace) + 1
+			edits.add(f, textEdit{start: lbrace, end: lbrace, text: "\nNewSpecification: " + value + ","})
+		},
+	}, true
+}
+
+// sameFields reports whether the connector literals set the same fields to
+// the same values, apart from NewSpecification.
+func (u UpdateAcceptanceTests) sameFields(f *goFile, lit *ast.CompositeLit, otherFile *goFile, other *ast.CompositeLit) bool {
+	fields := func(f *goFile, lit *ast.CompositeLit) (map[string]string, bool) {
+		values := make(map[string]string)
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				return nil, false
+			}
+			if key, ok := kv.Key.(*ast.Ident); ok && key.Name != "NewSpecification" {
+				values[key.Name] = f.text(kv.Value)
+			}
+		}
+		return values, true
+	}
+	a, ok := fields(f, lit)
+	if !ok {
+		return false
+	}
+	b, ok := fields(otherFile, other)
+	return ok && maps.Equal(a, b)
+}
+
+// callChange replaces a call of Configure or Parameters on a source or
+// destination created by a connector, e.g. in a custom driver hook:
+//
+//	dest := d.Connector().NewDestination()
+//	err := dest.Configure(ctx, d.DestinationConfig(t))
+//
+// The parameters are taken from the specification of the same connector.
+func (u UpdateAcceptanceTests) callChange(f *goFile, call *ast.CallExpr, alias string, hooks map[*ast.BlockStmt]string) (acceptanceChange, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !(sel.Sel.Name == "Configure" && len(call.Args) == 2 || sel.Sel.Name == "Parameters" && len(call.Args) == 0) {
+		return acceptanceChange{}, false
+	}
+	method := sel.Sel.Name + "()"
+
+	connector, p, ok := u.resolve(sel.X, 0)
+	if !ok {
+		hook := "the acceptance test"
+		for body, name := range hooks {
+			if body.Pos() <= call.Pos() && call.End() <= body.End() {
+				hook = "the custom driver hook " + name
+			}
+		}
+		return acceptanceChange{
+			pos:     call.Pos(),
+			warning: fmt.Sprintf("couldn't migrate the call of %s in %s, use %s.Util.ParseConfig and the parameters of the specification instead", method, hook, alias),
+		}, true
+	}
+
+	params := f.text(connector) + ".NewSpecification()." + p.iface + "Params"
+	return acceptanceChange{
+		pos:  call.Pos(),
+		note: fmt.Sprintf("replaced %s", method),
+		apply: func(edits *goEdits) {
+			replaceTestCall(f, call, alias, params, edits)
+		},
+	}, true
+}
+
+// resolve returns the connector creating the value of expr and the plugin of
+// the value, following local variables back to a call of NewSource or
+// NewDestination.
+func (u UpdateAcceptanceTests) resolve(expr ast.Expr, depth int) (ast.Expr, plugin, bool) {
+	if depth > 10 {
+		return nil, plugin{}, false
+	}
+	depth++
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok || len(e.Args) > 0 {
+			break
+		}
+		for _, p := range []plugin{sourcePlugin, destinationPlugin} {
+			if sel.Sel.Name == "New"+p.iface {
+				return sel.X, p, true
+			}
+		}
+	case *ast.Ident:
+		if e.Obj == nil {
+			break
+		}
+		switch decl := e.Obj.Decl.(type) {
+		case *ast.AssignStmt:
+			i := slices.IndexFunc(decl.Lhs, func(lhs ast.Expr) bool { return isIdent(lhs, e.Name) })
+			if i >= 0 && len(decl.Lhs) == len(decl.Rhs) {
+				return u.resolve(decl.Rhs[i], depth)
+			}
+		case *ast.ValueSpec:
+			i := slices.IndexFunc(decl.Names, func(name *ast.Ident) bool { return name.Name == e.Name })
+			if i >= 0 && len(decl.Names) == len(decl.Values) {
+				return u.resolve(decl.Values[i], depth)
+			}
+		}
+	}
+	return nil, plugin{}, false
+}
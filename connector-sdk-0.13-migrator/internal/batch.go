@@ -0,0 +1,234 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// Batch migrates many connectors in parallel. Every connector is migrated by
+// a separate process, so that a failure (or a crash) in one connector doesn't
+// affect the others.
+type Batch struct {
+	// Command is the migrator executable, usually os.Executable().
+	Command string
+	// Args are passed to Command for every connector, before the directory
+	// of the connector.
+	Args []string
+	// Jobs is the maximum number of connectors migrated at the same time.
+	Jobs int
+}
+
+// BatchStatus is the outcome of migrating a single connector in a batch.
+type BatchStatus string
+
+const (
+	BatchSucceeded BatchStatus = "succeeded"
+	BatchFailed    BatchStatus = "failed"
+	// BatchSkipped means that there was nothing to migrate.
+	BatchSkipped BatchStatus = "skipped"
+)
+
+// BatchResult is the result of migrating a single connector.
+type BatchResult struct {
+	Dir    string      `json:"dir"`
+	Status BatchStatus `json:"status"`
+	// FailedMigrator is the name of the migrator that failed, if any, or
+	// "verification" if the verification of the migrated connector failed.
+	FailedMigrator string  `json:"failedMigrator,omitempty"`
+	Error          string  `json:"error,omitempty"`
+	Report         *Report `json:"report,omitempty"`
+	// Output is the output of the migration process.
+	Output string `json:"-"`
+}
+
+// ExpandDirs expands the glob patterns to the matching directories. Patterns
+// without glob characters are returned as-is, duplicates are removed.
+func ExpandDirs(patterns []string) ([]string, error) {
+	var dirs []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			// Not a glob or nothing matches, let the migration report
+			// the missing directory.
+			matches = []string{pattern}
+		}
+		for _, m := range matches {
+			if info, err := os.Stat(m); err == nil && !info.IsDir() {
+				continue
+			}
+			if !slices.Contains(dirs, m) {
+				dirs = append(dirs, m)
+			}
+		}
+	}
+	return dirs, nil
+}
+
+// Run migrates the connectors in dirs and returns their results in the same
+// order.
+func (b Batch) Run(ctx context.Context, dirs []string) ([]BatchResult, error) {
+	tmpDir, err := os.MkdirTemp("", "connector-sdk-migrator-batch-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	results := make([]BatchResult, len(dirs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var outMu sync.Mutex
+	for range max(b.Jobs, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				reportPath := filepath.Join(tmpDir, fmt.Sprintf("report-%d.json", i))
+				results[i] = b.migrate(ctx, dirs[i], reportPath)
+
+				// Print the output of every connector in one piece, so the
+				// outputs of parallel migrations don't interleave.
+				outMu.Lock()
+				fmt.Printf("==> %s: %s\n%s\n", results[i].Dir, results[i].Status, results[i].Output)
+				outMu.Unlock()
+			}
+		}()
+	}
+	for i := range dirs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, nil
+}
+
+// migrate migrates a single connector in a separate process.
+func (b Batch) migrate(ctx context.Context, dir, reportPath string) BatchResult {
+	result := BatchResult{Dir: dir}
+
+	var out bytes.Buffer
+	args := append(slices.Clone(b.Args), "--report", reportPath, dir)
+	cmd := exec.CommandContext(ctx, b.Command, args...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	runErr := cmd.Run()
+	result.Output = out.String()
+
+	data, err := os.ReadFile(reportPath)
+	if err == nil {
+		result.Report = &Report{}
+		err = json.Unmarshal(data, result.Report)
+	}
+	if err != nil {
+		// Without a report it's unknown what happened.
+		result.Report = nil
+		if runErr == nil {
+			runErr = fmt.Errorf("failed to read report: %w", err)
+		}
+	}
+
+	switch {
+	case result.Report != nil && result.Report.Skipped != "":
+		result.Status = BatchSkipped
+		result.Error = result.Report.Skipped
+	case runErr != nil && result.Report != nil && result.Report.Verification != nil && !result.Report.Verification.Passed:
+		result.Status = BatchFailed
+		result.FailedMigrator = "verification"
+		result.Error = result.Report.Verification.summary()
+	case runErr != nil:
+		result.Status = BatchFailed
+		result.Error = runErr.Error()
+		if result.Report != nil && result.Report.Error != "" {
+			result.Error = strings.ReplaceAll(result.Report.Error, "\n", "; ")
+		}
+		if result.Report != nil {
+			for _, step := range result.Report.Migrators {
+				if step.Result == StepFailed {
+					result.FailedMigrator = step.Name
+				}
+			}
+		}
+	case result.Report.migrated():
+		result.Status = BatchSucceeded
+	default:
+		result.Status = BatchSkipped
+	}
+	return result
+}
+
+// summary returns the first problem found by the verification, or the
+// failed command if there are no problems.
+func (v *Verification) summary() string {
+	if len(v.Problems) > 0 {
+		msg := v.Problems[0].String()
+		if len(v.Problems) > 1 {
+			msg += fmt.Sprintf(" (and %d more)", len(v.Problems)-1)
+		}
+		return msg
+	}
+	return fmt.Sprintf("`%s` failed", v.Commands[len(v.Commands)-1].Command)
+}
+
+// migrated reports whether any migrator made changes.
+func (r *Report) migrated() bool {
+	for _, step := range r.Migrators {
+		if step.Result == StepMigrated {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteBatchReport writes the results, including the report of every
+// connector, as indented JSON to the named file.
+func WriteBatchReport(name string, results []BatchResult) error {
+	return writeJSON(name, results)
+}
+
+// WriteBatchSummary writes a table with the outcome of every connector to w.
+func WriteBatchSummary(w io.Writer, results []BatchResult) error {
+	counts := make(map[BatchStatus]int)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONNECTOR\tSTATUS\tFAILED MIGRATOR\tERROR")
+	for _, r := range results {
+		counts[r.Status]++
+		failed := r.FailedMigrator
+		if failed == "" {
+			failed = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Dir, r.Status, failed, r.Error)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d succeeded, %d failed, %d skipped\n",
+		counts[BatchSucceeded], counts[BatchFailed], counts[BatchSkipped])
+	return err
+}
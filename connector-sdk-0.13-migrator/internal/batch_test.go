@@ -0,0 +1,158 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestBatchMigrate(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+
+	testCases := []struct {
+		name     string
+		report   string // written by the migration, unless empty
+		exitCode int
+		want     BatchResult
+		// wantReport is whether the report can be read.
+		wantReport bool
+	}{{
+		name:       "migrated",
+		report:     `{"succeeded":true,"migrators":[{"name":"UpgradeSDK","result":"migrated"},{"name":"DeleteParamGen","result":"already applied"}]}`,
+		want:       BatchResult{Status: BatchSucceeded},
+		wantReport: true,
+	}, {
+		name:       "nothing to migrate",
+		report:     `{"succeeded":true,"migrators":[{"name":"UpgradeSDK","result":"already applied"}]}`,
+		want:       BatchResult{Status: BatchSkipped},
+		wantReport: true,
+	}, {
+		name:       "not a connector",
+		report:     `{"succeeded":true,"skipped":"go.mod doesn't require the connector SDK","migrators":[]}`,
+		want:       BatchResult{Status: BatchSkipped, Error: "go.mod doesn't require the connector SDK"},
+		wantReport: true,
+	}, {
+		name:     "migrator failed",
+		report:   `{"error":"migrator UpdateSourceGo failed:\nboom","migrators":[{"name":"UpgradeSDK","result":"migrated"},{"name":"UpdateSourceGo","result":"failed"}]}`,
+		exitCode: 1,
+		want: BatchResult{
+			Status:         BatchFailed,
+			FailedMigrator: "UpdateSourceGo",
+			Error:          "migrator UpdateSourceGo failed:; boom",
+		},
+		wantReport: true,
+	}, {
+		name:     "verification found problems",
+		report:   `{"migrators":[{"name":"UpgradeSDK","result":"migrated"}],"verification":{"passed":false,"problems":[{"file":"source/source.go","line":12,"column":2,"message":"undefined: x"},{"file":"connector.go","line":3,"message":"undefined: y"}]}}`,
+		exitCode: 1,
+		want: BatchResult{
+			Status:         BatchFailed,
+			FailedMigrator: "verification",
+			Error:          "source/source.go:12:2: undefined: x (and 1 more)",
+		},
+		wantReport: true,
+	}, {
+		name:     "verification command failed",
+		report:   `{"migrators":[{"name":"UpgradeSDK","result":"migrated"}],"verification":{"passed":false,"commands":[{"command":"go build ./..."},{"command":"go test ./..."}],"problems":[]}}`,
+		exitCode: 1,
+		want: BatchResult{
+			Status:         BatchFailed,
+			FailedMigrator: "verification",
+			Error:          "`go test ./...` failed",
+		},
+		wantReport: true,
+	}, {
+		name:     "crashed without report",
+		exitCode: 2,
+		want:     BatchResult{Status: BatchFailed, Error: "exit status 2"},
+	}, {
+		name: "succeeded without report",
+		want: BatchResult{Status: BatchFailed, Error: "failed to read report: "},
+	}, {
+		name:   "invalid report",
+		report: `{"migrators":`,
+		want:   BatchResult{Status: BatchFailed, Error: "failed to read report: unexpected end of JSON input"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			// The fake migrator is called with --report <path> <dir>.
+			script := fmt.Sprintf("echo migrating $3; exit %d", tc.exitCode)
+			if tc.report != "" {
+				src := filepath.Join(dir, "report.json")
+				if err := os.WriteFile(src, []byte(tc.report), 0644); err != nil {
+					t.Fatal(err)
+				}
+				script = fmt.Sprintf("cp '%s' \"$2\"; %s", src, script)
+			}
+			b := Batch{Command: "sh", Args: []string{"-c", script, "sh"}}
+
+			got := b.migrate(context.Background(), "connector", filepath.Join(dir, "out.json"))
+
+			if got.Dir != "connector" || got.Status != tc.want.Status || got.FailedMigrator != tc.want.FailedMigrator {
+				t.Errorf("expected %s %s %q, got %s %s %q",
+					"connector", tc.want.Status, tc.want.FailedMigrator, got.Dir, got.Status, got.FailedMigrator)
+			}
+			// The error of a missing report depends on the OS.
+			if !strings.HasPrefix(got.Error, tc.want.Error) || tc.want.Error == "" && got.Error != "" {
+				t.Errorf("expected error %q, got %q", tc.want.Error, got.Error)
+			}
+			if (got.Report != nil) != tc.wantReport {
+				t.Errorf("unexpected report %+v", got.Report)
+			}
+			if got.Output != "migrating connector\n" {
+				t.Errorf("expected the output of the migration, got %q", got.Output)
+			}
+		})
+	}
+}
+
+func TestWriteBatchSummary(t *testing.T) {
+	results := []BatchResult{
+		{Dir: "conduit-connector-file", Status: BatchSucceeded},
+		{Dir: "conduit-connector-kafka", Status: BatchFailed, FailedMigrator: "verification", Error: "source/source.go:12:2: undefined: x"},
+		{Dir: "tools", Status: BatchSkipped, Error: "go.mod doesn't require the connector SDK"},
+		{Dir: "conduit-connector-s3", Status: BatchSucceeded},
+	}
+	want := `CONNECTOR                STATUS     FAILED MIGRATOR  ERROR
+conduit-connector-file   succeeded  -
+conduit-connector-kafka  failed     verification     source/source.go:12:2: undefined: x
+tools                    skipped    -                go.mod doesn't require the connector SDK
+conduit-connector-s3     succeeded  -
+
+2 succeeded, 1 failed, 1 skipped
+`
+
+	var buf bytes.Buffer
+	if err := WriteBatchSummary(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	// The empty error column is padded, trailing spaces aren't compared.
+	got := regexp.MustCompile(` +\n`).ReplaceAllString(buf.String(), "\n")
+	if got != want {
+		t.Errorf("unexpected summary\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -15,28 +15,74 @@
 package internal
 
 import (
-	"os"
-	"path/filepath"
+	"context"
+	"errors"
+	"io/fs"
+	"path"
 )
 
 type Migrator interface {
-	Migrate(workingDir string) error
+	// Description is a short summary of the changes made by the migrator. It's
+	// used as the commit message when committing the migration.
+	Description() string
+	// Check reports whether the migrator needs to run, without changing
+	// anything.
+	Check(fsys FS) (Status, error)
+	Migrate(ctx context.Context, fsys FS) error
 }
 
-// readFile reads filePath in workingDir. Returns: path, contents, error
-func readFile(workingDir, filePath string) (string, string, error) {
-	p := filepath.Join(workingDir, filePath)
+// configureTODO starts the comment left on Configure methods, which need to
+// be removed manually.
+const configureTODO = "TODO: This method needs to be removed."
+
+// middlewareTODO starts the comment left on calls of SourceWithMiddleware and
+// DestinationWithMiddleware passing options to the default middleware.
+const middlewareTODO = "TODO: The options of the default middleware need to be migrated."
+
+// Status describes whether a migrator needs to run on a connector.
+type Status int
+
+const (
+	StatusNeedsMigration Status = iota
+	StatusAlreadyApplied
+	StatusNotApplicable
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusNeedsMigration:
+		return "needs migration"
+	case StatusAlreadyApplied:
+		return "already applied"
+	case StatusNotApplicable:
+		return "not applicable"
+	}
+	return "unknown"
+}
+
+// readFile reads filePath in fsys. Returns: path, contents, error
+func readFile(fsys FS, filePath string) (string, string, error) {
+	p := path.Clean(filePath)
 
 	// Check if file exists
-	_, err := os.Stat(p)
+	_, err := fsys.Stat(p)
 	if err != nil {
 		return "", "", err
 	}
 
-	contents, err := os.ReadFile(p)
+	contents, err := fsys.ReadFile(p)
 	if err != nil {
 		return "", "", err
 	}
 
 	return p, string(contents), nil
 }
+
+// fileExists reports whether name exists in fsys.
+func fileExists(fsys FS, name string) (bool, error) {
+	_, err := fsys.Stat(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
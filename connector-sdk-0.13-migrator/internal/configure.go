@@ -0,0 +1,720 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// configure is the Configure method of an implementation being migrated. In
+// SDK v0.13, the configuration is parsed by the SDK and validated by its
+// Validate method, so the usual pattern of
+//
+//	err := sdk.Util.ParseConfig(ctx, cfg, &s.config, NewSource().Parameters())
+//	if err != nil {
+//		return err
+//	}
+//	// custom validations of s.config
+//	return nil
+//
+// is migrated by moving the custom validations to a Validate method of the
+// configuration struct and removing Configure.
+type configure struct {
+	impl *implementation
+	fn   *ast.FuncDecl
+	file *goFile
+
+	recv  string
+	ctx   string
+	field string
+	// pointer is true if the configuration field is a pointer.
+	pointer bool
+
+	// stmts are the statements of the body, except for the final return.
+	stmts []ast.Stmt
+	// parse is the range of statements parsing the configuration.
+	parse [2]int
+	// moved are the statements moved to Validate, by index.
+	moved map[int]bool
+}
+
+// configureEdits adds the edits migrating the Configure method of the
+// implementation. Statements that can't be moved to Validate are left in
+// Configure, which is marked with a TODO. It returns a note describing what
+// was migrated.
+func (p plugin) configureEdits(impl *implementation, edits *goEdits) string {
+	m := impl.methods["Configure"]
+	if m == nil || impl.methods["Config"] != nil {
+		return ""
+	}
+
+	c := &configure{impl: impl, fn: m.decl, file: m.file}
+	cfgType, ok := c.analyze()
+	if !ok {
+		c.todo(edits)
+		return ""
+	}
+	validate, err := c.validate(cfgType, edits)
+	if err != nil {
+		c.todo(edits)
+		return ""
+	}
+
+	pkg := impl.pkg
+	if c.complete() {
+		edits.remove(pkg, c.file, c.fn)
+	} else {
+		for i := range c.stmts {
+			if c.removed(i) {
+				// The removed statement is replaced by the next one.
+				end := pkg.offset(c.stmts[i].End())
+				if i+1 < len(c.fn.Body.List) {
+					end = c.stmtStart(i + 1)
+				}
+				edits.add(c.file, textEdit{start: c.stmtStart(i), end: end})
+			}
+		}
+		if len(c.stmts) == len(c.fn.Body.List) && c.removed(len(c.stmts)-1) {
+			// The removed statement returned from Configure.
+			rbrace := pkg.offset(c.fn.Body.Rbrace)
+			edits.add(c.file, textEdit{start: rbrace, end: rbrace, text: "\nreturn nil\n"})
+		}
+		c.todo(edits)
+	}
+
+	switch {
+	case validate && c.complete():
+		return fmt.Sprintf("moved %s.Configure to %s.Validate", impl.name, cfgType.spec.Name.Name)
+	case validate:
+		return fmt.Sprintf("moved parts of %s.Configure to %s.Validate", impl.name, cfgType.spec.Name.Name)
+	case c.complete():
+		return fmt.Sprintf("removed %s.Configure", impl.name)
+	}
+	return ""
+}
+
+// todo marks Configure with a TODO.
+func (c *configure) todo(edits *goEdits) {
+	pos := c.impl.pkg.offset(c.fn.Pos())
+	todo := "// " + configureTODO + " If there's any custom logic in Configure(),\n" +
+		"// it needs to be moved to the configuration struct in the Validate() method.\n"
+	edits.add(c.file, textEdit{start: pos, end: pos, text: todo})
+}
+
+// configType is the declaration of the configuration struct.
+type configType struct {
+	file *goFile
+	decl *ast.GenDecl
+	spec *ast.TypeSpec
+}
+
+// analyze finds the call parsing the configuration and decides which
+// statements can be moved to Validate. It reports false if Configure doesn't
+// follow the usual pattern.
+func (c *configure) analyze() (configType, bool) {
+	pkg := c.impl.pkg
+	alias, ok := importName(c.file.ast, sdkModule)
+	if !ok || c.fn.Body == nil {
+		return configType{}, false
+	}
+
+	c.recv = fieldName(c.fn.Recv.List[0])
+	params := c.fn.Type.Params.List
+	if len(params) == 0 {
+		return configType{}, false
+	}
+	c.ctx = fieldName(params[0])
+	// Statements using the raw configuration can't be moved.
+	var rawConfig string
+	if len(params[0].Names) > 1 {
+		rawConfig = params[0].Names[1].Name
+	} else if len(params) > 1 {
+		rawConfig = fieldName(params[1])
+	}
+
+	c.stmts = c.fn.Body.List
+	if n := len(c.stmts); n > 0 && isReturnNil(c.stmts[n-1]) {
+		c.stmts = c.stmts[:n-1]
+	}
+
+	start, end, target := c.findParse(alias)
+	if target == nil {
+		return configType{}, false
+	}
+	c.parse = [2]int{start, end}
+
+	// &s.config or s.config, if the field is a pointer
+	if unary, ok := target.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		target = unary.X
+	} else {
+		c.pointer = true
+	}
+	sel, ok := target.(*ast.SelectorExpr)
+	if !ok || !isIdent(sel.X, c.recv) || c.recv == "" {
+		return configType{}, false
+	}
+	c.field = sel.Sel.Name
+
+	typeName, pointer, ok := structFieldType(c.impl.spec, c.field)
+	if !ok || pointer != c.pointer {
+		return configType{}, false
+	}
+	cfgType, ok := pkg.typeDecl(typeName)
+	if !ok || pkg.methods(typeName)["Validate"] != nil {
+		return configType{}, false
+	}
+
+	// Locals declared before the configuration is parsed or by the parsing
+	// statements aren't available in Validate.
+	unavailable := map[string]bool{}
+	if rawConfig != "" && rawConfig != "_" {
+		unavailable[rawConfig] = true
+	}
+	for i := 0; i < c.parse[1]; i++ {
+		for _, name := range declaredNames(c.stmts[i]) {
+			unavailable[name] = true
+		}
+	}
+
+	c.moved = make(map[int]bool)
+	declaredBy := make(map[string]int)
+	for i := c.parse[1]; i < len(c.stmts); i++ {
+		c.moved[i] = c.movable(c.stmts[i], unavailable, cfgType.file)
+		for _, name := range declaredNames(c.stmts[i]) {
+			declaredBy[name] = i
+		}
+	}
+	// Statements that stay in Configure need the locals they use, so the
+	// statements declaring them have to stay as well.
+	for changed := true; changed; {
+		changed = false
+		for i, stmt := range c.stmts {
+			if c.removed(i) {
+				continue
+			}
+			for name := range usedNames(stmt) {
+				if j, ok := declaredBy[name]; ok && c.moved[j] {
+					c.moved[j] = false
+					changed = true
+				}
+			}
+		}
+		for i := c.parse[1]; i < len(c.stmts); i++ {
+			if !c.moved[i] {
+				continue
+			}
+			for name := range usedNames(c.stmts[i]) {
+				if j, ok := declaredBy[name]; ok && j < i && !c.moved[j] {
+					c.moved[i] = false
+					changed = true
+				}
+			}
+		}
+	}
+
+	// The locals declared by the parsing statements are removed with them.
+	for i := c.parse[1]; i < len(c.stmts); i++ {
+		if c.moved[i] {
+			continue
+		}
+		for j := c.parse[0]; j < c.parse[1]; j++ {
+			for _, name := range declaredNames(c.stmts[j]) {
+				if usedNames(c.stmts[i])[name] {
+					return configType{}, false
+				}
+			}
+		}
+	}
+	return cfgType, true
+}
+
+// findParse returns the range of statements parsing the configuration with
+// sdk.Util.ParseConfig, including the error check, and the target the
+// configuration is parsed into.
+func (c *configure) findParse(alias string) (int, int, ast.Expr) {
+	isParse := func(expr ast.Expr) (ast.Expr, bool) {
+		call, ok := expr.(*ast.CallExpr)
+		if !ok || len(call.Args) != 4 {
+			return nil, false
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "ParseConfig" || !isSelector(sel.X, alias, "Util") {
+			return nil, false
+		}
+		return call.Args[2], true
+	}
+
+	for i, stmt := range c.stmts {
+		switch stmt := stmt.(type) {
+		case *ast.AssignStmt:
+			// err := sdk.Util.ParseConfig(...)
+			// if err != nil { ... }
+			if len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 {
+				continue
+			}
+			target, ok := isParse(stmt.Rhs[0])
+			if !ok || i+1 == len(c.stmts) || !isErrCheck(c.stmts[i+1], stmt.Lhs[0]) {
+				continue
+			}
+			return i, i + 2, target
+		case *ast.IfStmt:
+			// if err := sdk.Util.ParseConfig(...); err != nil { ... }
+			assign, ok := stmt.Init.(*ast.AssignStmt)
+			if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+				continue
+			}
+			if target, ok := isParse(assign.Rhs[0]); ok && isErrCheck(&ast.IfStmt{Cond: stmt.Cond, Body: stmt.Body, Else: stmt.Else}, assign.Lhs[0]) {
+				return i, i + 1, target
+			}
+		case *ast.ReturnStmt:
+			// return sdk.Util.ParseConfig(...)
+			if len(stmt.Results) != 1 {
+				continue
+			}
+			if target, ok := isParse(stmt.Results[0]); ok {
+				return i, i + 1, target
+			}
+		}
+	}
+	return 0, 0, nil
+}
+
+// movable reports whether the statement only uses the configuration, the
+// context and locals that are available in Validate. The statement mustn't
+// use other fields of the receiver, since they aren't set when the
+// configuration is validated.
+func (c *configure) movable(stmt ast.Stmt, unavailable map[string]bool, target *goFile) bool {
+	for name := range usedNames(stmt) {
+		if unavailable[name] {
+			return false
+		}
+	}
+
+	movable := true
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			if isIdent(n.X, c.recv) {
+				if n.Sel.Name != c.field {
+					movable = false
+				}
+				return false
+			}
+			if ident, ok := n.X.(*ast.Ident); ok && ident.Obj == nil && target != c.file {
+				// The package has to be imported with the same name in
+				// the file of the configuration.
+				importPath, ok := importPathOf(c.file.ast, ident.Name)
+				if name, imported := importName(target.ast, importPath); ok && imported && name != ident.Name {
+					movable = false
+				}
+				if other, ok := importPathOf(target.ast, ident.Name); ok && other != importPath {
+					movable = false
+				}
+			}
+		case *ast.Ident:
+			if n.Name == c.recv {
+				movable = false
+			}
+		case *ast.DeferStmt, *ast.GoStmt, *ast.LabeledStmt:
+			movable = false
+		case *ast.BranchStmt:
+			if n.Tok == token.GOTO {
+				movable = false
+			}
+		}
+		return movable
+	})
+	return movable
+}
+
+// removed reports whether the statement is removed from Configure: it's
+// moved to Validate, parses the configuration or logs before parsing it.
+func (c *configure) removed(i int) bool {
+	if i >= c.parse[0] && i < c.parse[1] {
+		return true
+	}
+	if i < c.parse[0] {
+		return isLogging(c.stmts[i], c.file.ast)
+	}
+	return c.moved[i]
+}
+
+// complete reports whether all statements are removed from Configure.
+func (c *configure) complete() bool {
+	for i := range c.stmts {
+		if !c.removed(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// validate adds the Validate method with the moved statements after the
+// declaration of the configuration struct. It reports false if no statements
+// are moved.
+func (c *configure) validate(cfgType configType, edits *goEdits) (bool, error) {
+	var body []string
+	for i := range c.stmts {
+		if c.moved[i] {
+			text, err := c.movedText(i)
+			if err != nil {
+				return false, err
+			}
+			body = append(body, text)
+		}
+	}
+	if len(body) == 0 {
+		return false, nil
+	}
+	if _, ok := c.stmts[len(c.stmts)-1].(*ast.ReturnStmt); !ok || !c.moved[len(c.stmts)-1] {
+		body = append(body, "return nil")
+	}
+
+	// Packages used by the moved statements have to be imported.
+	for i := range c.stmts {
+		if !c.moved[i] {
+			continue
+		}
+		for name := range usedNames(c.stmts[i]) {
+			if importPath, ok := importPathOf(c.file.ast, name); ok {
+				edits.addImport(cfgType.file, importPath, explicitImportName(c.file.ast, importPath))
+			}
+		}
+	}
+	contextName, ok := importName(cfgType.file.ast, "context")
+	if !ok {
+		contextName = "context"
+		edits.addImport(cfgType.file, "context", "")
+	}
+
+	ctx := c.ctx
+	if ctx == "" {
+		ctx = "_"
+	}
+	end := c.impl.pkg.offset(cfgType.decl.End())
+	text := fmt.Sprintf("\n\nfunc (%s *%s) Validate(%s %s.Context) error {\n\t%s\n}",
+		c.validateReceiver(), cfgType.spec.Name.Name, ctx, contextName, strings.Join(body, "\n\t"))
+	edits.add(cfgType.file, textEdit{start: end, end: end, text: text})
+	return true, nil
+}
+
+// validateReceiver returns a receiver name for Validate that isn't used by
+// the moved statements.
+func (c *configure) validateReceiver() string {
+	used := map[string]bool{c.ctx: true}
+	for i := range c.stmts {
+		if c.moved[i] {
+			for name := range usedNames(c.stmts[i]) {
+				used[name] = true
+			}
+		}
+	}
+	for _, name := range []string{"c", "cfg", "conf", "config"} {
+		if !used[name] {
+			return name
+		}
+	}
+	return "config_"
+}
+
+// movedText returns the statement with its preceding comments, with the
+// configuration field replaced by the receiver of Validate.
+func (c *configure) movedText(i int) (string, error) {
+	pkg := c.impl.pkg
+	start, end := c.stmtStart(i), pkg.offset(c.stmts[i].End())
+	recv := c.validateReceiver()
+
+	var edits []textEdit
+	replace := func(n ast.Node, text string) {
+		edits = append(edits, textEdit{start: pkg.offset(n.Pos()) - start, end: pkg.offset(n.End()) - start, text: text})
+	}
+	var stack []ast.Node
+	ast.Inspect(c.stmts[i], func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		defer func() { stack = append(stack, n) }()
+
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok || !isIdent(sel.X, c.recv) || sel.Sel.Name != c.field {
+			return true
+		}
+		parent := stack[len(stack)-1]
+		switch {
+		case c.pointer:
+			replace(sel, recv)
+		case isAddressOf(parent, sel):
+			replace(parent, recv)
+		case isSelectorOf(parent, sel):
+			replace(sel, recv)
+		default:
+			replace(sel, "(*"+recv+")")
+		}
+		// The children aren't visited, so the node isn't pushed.
+		stack = append(stack, n)
+		return false
+	})
+	text, err := applyEdits(c.file.content[start:end], edits)
+	return string(text), err
+}
+
+// stmtStart returns the offset of the statement, including the comments
+// preceding it.
+func (c *configure) stmtStart(i int) int {
+	prev := c.fn.Body.Lbrace
+	if i > 0 {
+		prev = c.fn.Body.List[i-1].End()
+	}
+	pos := c.fn.Body.List[i].Pos()
+	for _, group := range c.file.ast.Comments {
+		if group.Pos() > prev && group.Pos() < pos {
+			pos = group.Pos()
+			break
+		}
+	}
+	return c.impl.pkg.offset(pos)
+}
+
+// typeDecl returns the declaration of the named struct type.
+func (p *goPackage) typeDecl(name string) (configType, bool) {
+	for _, f := range p.files {
+		for _, decl := range f.ast.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if _, ok := typeSpec.Type.(*ast.StructType); ok && typeSpec.Name.Name == name {
+					return configType{file: f, decl: gen, spec: typeSpec}, true
+				}
+			}
+		}
+	}
+	return configType{}, false
+}
+
+// structFieldType returns the name of the type of the field of the struct,
+// if it's a type of the same package, and whether it's a pointer.
+func structFieldType(spec *ast.TypeSpec, field string) (string, bool, bool) {
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return "", false, false
+	}
+	for _, f := range st.Fields.List {
+		for _, name := range f.Names {
+			if name.Name != field {
+				continue
+			}
+			typ, pointer := f.Type, false
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ, pointer = star.X, true
+			}
+			if ident, ok := typ.(*ast.Ident); ok {
+				return ident.Name, pointer, true
+			}
+			return "", false, false
+		}
+	}
+	return "", false, false
+}
+
+// fieldName returns the name of the first name of the parameter or
+// receiver, or an empty string if it has none.
+func fieldName(f *ast.Field) string {
+	if len(f.Names) == 0 {
+		return ""
+	}
+	return f.Names[0].Name
+}
+
+// isLogging reports whether the statement only logs, e.g.
+// sdk.Logger(ctx).Info().Msg("Configuring Source...").
+func isLogging(stmt ast.Stmt, file *ast.File) bool {
+	exprStmt, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+	alias, ok := importName(file, sdkModule)
+	if !ok {
+		return false
+	}
+	expr := exprStmt.X
+	for {
+		call, ok := expr.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		if isSelector(call.Fun, alias, "Logger") {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return false
+		}
+		expr = sel.X
+	}
+}
+
+// isErrCheck reports whether stmt is `if err != nil { ... }` without an else
+// branch.
+func isErrCheck(stmt ast.Stmt, err ast.Expr) bool {
+	ifStmt, ok := stmt.(*ast.IfStmt)
+	if !ok || ifStmt.Else != nil {
+		return false
+	}
+	ident, ok := err.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	cond, ok := ifStmt.Cond.(*ast.BinaryExpr)
+	return ok && cond.Op == token.NEQ && isIdent(cond.X, ident.Name) && isIdent(cond.Y, "nil")
+}
+
+// isReturnNil reports whether stmt is `return nil`.
+func isReturnNil(stmt ast.Stmt) bool {
+	ret, ok := stmt.(*ast.ReturnStmt)
+	return ok && len(ret.Results) == 1 && isIdent(ret.Results[0], "nil")
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+func isAddressOf(parent ast.Node, x ast.Expr) bool {
+	unary, ok := parent.(*ast.UnaryExpr)
+	return ok && unary.Op == token.AND && unary.X == x
+}
+
+func isSelectorOf(parent ast.Node, x ast.Expr) bool {
+	sel, ok := parent.(*ast.SelectorExpr)
+	return ok && sel.X == x
+}
+
+// declaredNames returns the names declared by the statement in the scope of
+// the function body.
+func declaredNames(stmt ast.Stmt) []string {
+	var names []string
+	switch stmt := stmt.(type) {
+	case *ast.AssignStmt:
+		if stmt.Tok == token.DEFINE {
+			for _, lhs := range stmt.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && ident.Name != "_" {
+					names = append(names, ident.Name)
+				}
+			}
+		}
+	case *ast.DeclStmt:
+		if gen, ok := stmt.Decl.(*ast.GenDecl); ok {
+			for _, spec := range gen.Specs {
+				switch spec := spec.(type) {
+				case *ast.ValueSpec:
+					for _, ident := range spec.Names {
+						names = append(names, ident.Name)
+					}
+				case *ast.TypeSpec:
+					names = append(names, spec.Name.Name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// usedNames returns the identifiers used in the statement, except for
+// selected fields and methods and names declared within the statement, like
+// in `if err := c.validate(); err != nil`.
+func usedNames(stmt ast.Stmt) map[string]bool {
+	names := make(map[string]bool)
+	declared := make(map[string]bool)
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			ast.Inspect(n.X, visit)
+			return false
+		case *ast.KeyValueExpr:
+			// Keys of struct literals are field names.
+			if _, ok := n.Key.(*ast.Ident); ok {
+				ast.Inspect(n.Value, visit)
+				return false
+			}
+		case *ast.AssignStmt:
+			if n != stmt {
+				for _, name := range declaredNames(n) {
+					declared[name] = true
+				}
+			}
+		case *ast.RangeStmt:
+			if n.Tok == token.DEFINE {
+				for _, expr := range []ast.Expr{n.Key, n.Value} {
+					if ident, ok := expr.(*ast.Ident); ok {
+						declared[ident.Name] = true
+					}
+				}
+			}
+		case *ast.FuncType:
+			for _, list := range []*ast.FieldList{n.Params, n.Results} {
+				if list == nil {
+					continue
+				}
+				for _, f := range list.List {
+					for _, name := range f.Names {
+						declared[name.Name] = true
+					}
+				}
+			}
+		case *ast.Ident:
+			names[n.Name] = true
+		}
+		return true
+	}
+	ast.Inspect(stmt, visit)
+	for name := range declared {
+		delete(names, name)
+	}
+	return names
+}
+
+// importPathOf returns the path of the package imported with the given name.
+func importPathOf(file *ast.File, name string) (string, bool) {
+	for _, imp := range file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		if n, _ := importName(file, importPath); n == name {
+			return importPath, true
+		}
+	}
+	return "", false
+}
+
+// explicitImportName returns the name the package is explicitly imported
+// as, or an empty string.
+func explicitImportName(file *ast.File, importPath string) string {
+	for _, imp := range file.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == importPath && imp.Name != nil {
+			return imp.Name.Name
+		}
+	}
+	return ""
+}
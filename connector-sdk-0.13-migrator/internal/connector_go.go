@@ -15,70 +15,283 @@
 package internal
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
-	"regexp"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path"
+	"strconv"
 	"strings"
 )
 
+const (
+	specgenDirective = "//go:generate conn-sdk-cli specgen"
+	embedDirective   = "//go:embed connector.yaml"
+)
+
 type ConnectorGoMigrator struct {
 }
 
-func (a ConnectorGoMigrator) Migrate(workingDir string) error {
-	connectorGoPath, connectorGo, err := readFile(workingDir, "connector.go")
+func (a ConnectorGoMigrator) Description() string {
+	return "Load the connector specification from connector.yaml"
+}
+
+func (a ConnectorGoMigrator) Check(fsys FS) (Status, error) {
+	content, err := fsys.ReadFile("connector.go")
+	if errors.Is(err, fs.ErrNotExist) {
+		return StatusNotApplicable, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), "connector.go", content, parser.ParseComments)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing connector.go: %w", err)
+	}
+	if hasComment(file, embedDirective) {
+		return StatusAlreadyApplied, nil
+	}
+	return StatusNeedsMigration, nil
+}
+
+func (a ConnectorGoMigrator) Migrate(ctx context.Context, fsys FS) error {
+	connectorGoPath := "connector.go"
+	f, err := parseGoFile(fsys, token.NewFileSet(), connectorGoPath)
 	if err != nil {
 		return err
 	}
+	file, content := f.ast, f.content
+	report := StepReportFromContext(ctx)
 
-	updatedConnectorGo := strings.ReplaceAll(
-		connectorGo,
-		`// limitations under the License.`,
-		`// limitations under the License.
+	alias, ok := importName(file, sdkModule)
+	if !ok {
+		return fmt.Errorf("%s doesn't import %s", connectorGoPath, sdkModule)
+	}
 
-//go:generate conn-sdk-cli specgen`,
-	)
+	edits := newGoEdits()
 
-	// import embed
-	updatedConnectorGo = strings.ReplaceAll(updatedConnectorGo,
-		`import (
-`,
-		`import (
-	_ "embed"
-`)
+	// The directive is placed before the package clause and its doc
+	// comment, separated by an empty line, so it's not part of the doc.
+	if !hasComment(file, specgenDirective) {
+		pos := file.Package
+		if file.Doc != nil {
+			pos = file.Doc.Pos()
+		}
+		edits.add(f, textEdit{start: f.offset(pos), end: f.offset(pos), text: specgenDirective + "\n\n"})
+	}
+	edits.addImport(f, "embed", "_")
 
-	// import embed
-	updatedConnectorGo = strings.ReplaceAll(updatedConnectorGo,
-		`import sdk "github.com/conduitio/conduit-connector-sdk"`,
-		`import (
-	_ "embed"
+	// The specification is loaded from connector.yaml, embedded before the
+	// first connector declaration.
+	literals, decl := a.connectorLiterals(file, alias)
+	dir := path.Dir(connectorGoPath)
+	specs, err := a.specsName(fsys, dir, file)
+	if err != nil {
+		return err
+	}
+	if specs != "specs" {
+		report.Warnf("the package already declares specs, connector.yaml is embedded as %s", specs)
+	}
+	vars := embedDirective + "\nvar " + specs + " string\n\n"
+	declared, err := a.declares(fsys, dir, file, "version")
+	if err != nil {
+		return err
+	}
+	if !declared {
+		vars += "var version = \"(devel)\"\n\n"
+	}
+	newSpecification := alias + ".YAMLSpecification(" + specs + ", version)"
+	if decl == nil {
+		report.Warnf("%s doesn't declare an %s.Connector, set NewSpecification to %s manually", connectorGoPath, alias, newSpecification)
+		edits.add(f, textEdit{start: len(content), end: len(content), text: "\n" + vars})
+	} else {
+		pos := decl.Pos()
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			if decl.Doc != nil {
+				pos = decl.Doc.Pos()
+			}
+		case *ast.FuncDecl:
+			if decl.Doc != nil {
+				pos = decl.Doc.Pos()
+			}
+		}
+		edits.add(f, textEdit{start: f.offset(pos), end: f.offset(pos), text: vars})
+	}
 
-	sdk "github.com/conduitio/conduit-connector-sdk"
-)
-`)
+	for _, lit := range literals {
+		if kv := a.newSpecification(lit); kv != nil {
+			edits.add(f, textEdit{start: f.offset(kv.Value.Pos()), end: f.offset(kv.Value.End()), text: newSpecification})
+			continue
+		}
+		// Formatting fixes the indentation.
+		lbrace := f.offset(lit.Lbrace) + 1
+		edits.add(f, textEdit{start: lbrace, end: lbrace, text: "\nNewSpecification: " + newSpecification + ","})
+	}
+
+	if _, err := edits.write(fsys); err != nil {
+		return err
+	}
 
-	updatedConnectorGo = strings.ReplaceAll(updatedConnectorGo,
-		"var Connector = sdk.Connector{",
-		`//go:embed connector.yaml
-var specs string
+	fmt.Printf("Updated connector.go target in %s\n", connectorGoPath)
 
-var version = "(devel)"
+	return nil
+}
+
+// connectorLiterals returns all <alias>.Connector composite literals and the
+// top-level declaration containing the first one.
+func (a ConnectorGoMigrator) connectorLiterals(file *ast.File, alias string) ([]*ast.CompositeLit, ast.Decl) {
+	var literals []*ast.CompositeLit
+	var first ast.Decl
+	for _, decl := range file.Decls {
+		ast.Inspect(decl, func(n ast.Node) bool {
+			lit, ok := n.(*ast.CompositeLit)
+			if !ok || !isSelector(lit.Type, alias, "Connector") {
+				return true
+			}
+			literals = append(literals, lit)
+			if first == nil {
+				first = decl
+			}
+			return true
+		})
+	}
+	return literals, first
+}
 
-var Connector = sdk.Connector{`,
-	)
+// newSpecification returns the NewSpecification field of the literal.
+func (a ConnectorGoMigrator) newSpecification(lit *ast.CompositeLit) *ast.KeyValueExpr {
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "NewSpecification" {
+			return kv
+		}
+	}
+	return nil
+}
 
-	// Compile the regex pattern
-	regex := regexp.MustCompile(`NewSpecification:.*`)
+// specsName returns the name of the variable connector.yaml is embedded in:
+// specs, or connectorSpecs if the package already declares specs.
+func (a ConnectorGoMigrator) specsName(fsys FS, dir string, file *ast.File) (string, error) {
+	for _, name := range []string{"specs", "connectorSpecs"} {
+		declared, err := a.declares(fsys, dir, file, name)
+		if err != nil {
+			return "", err
+		}
+		if !declared {
+			return name, nil
+		}
+	}
+	return "", errors.New("the package already declares specs and connectorSpecs, embed connector.yaml manually")
+}
 
-	// Replace the line with the new specification
-	updatedConnectorGo = regex.ReplaceAllString(updatedConnectorGo, "NewSpecification: sdk.YAMLSpecification(specs, version),")
+// declares reports whether the package of file already declares a variable
+// or constant with the given name. spec.go is ignored, since it's deleted by
+// DeleteSpecGo.
+func (a ConnectorGoMigrator) declares(fsys FS, dir string, file *ast.File, name string) (bool, error) {
+	if declaresVar(file, name) {
+		return true, nil
+	}
 
-	// Write back to file
-	err = os.WriteFile(connectorGoPath, []byte(updatedConnectorGo), 0644)
+	var others []string
+	err := fsys.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && p != dir {
+			return fs.SkipDir
+		}
+		base := d.Name()
+		if strings.HasSuffix(base, ".go") && !strings.HasSuffix(base, "_test.go") && base != "spec.go" && base != "connector.go" {
+			others = append(others, p)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("could not write to %s: %v", connectorGoPath, err)
+		return false, fmt.Errorf("error walking dir: %w", err)
 	}
 
-	fmt.Printf("Updated connector.go target in %s\n", connectorGoPath)
+	for _, other := range others {
+		content, err := fsys.ReadFile(other)
+		if err != nil {
+			return false, err
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), other, content, 0)
+		if err != nil {
+			return false, fmt.Errorf("error parsing %s: %w", other, err)
+		}
+		if f.Name.Name == file.Name.Name && declaresVar(f, name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
 
-	return nil
+// declaresVar reports whether file declares a package-level variable or
+// constant with the given name.
+func declaresVar(file *ast.File, name string) bool {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || (gen.Tok != token.VAR && gen.Tok != token.CONST) {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			for _, ident := range spec.(*ast.ValueSpec).Names {
+				if ident.Name == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// importName returns the name the package with the given path is imported
+// as in file. If the import has no explicit name, the last element of the
+// path is returned (e.g. "sdk" for the SDK, whose package is named like
+// that).
+func importName(file *ast.File, importPath string) (string, bool) {
+	for _, imp := range file.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || p != importPath {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name, true
+		}
+		if importPath == sdkModule {
+			return "sdk", true
+		}
+		return path.Base(importPath), true
+	}
+	return "", false
+}
+
+// isSelector reports whether expr is pkg.name.
+func isSelector(expr ast.Expr, pkg, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == pkg
+}
+
+// hasComment reports whether file contains a line comment with the given
+// text.
+func hasComment(file *ast.File, text string) bool {
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			if strings.TrimSpace(c.Text) == text {
+				return true
+			}
+		}
+	}
+	return false
 }
@@ -0,0 +1,416 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// specificationHelper is the name of the function added to test files of
+// packages that can't use the Connector variable, returning the connector
+// specification from connector.yaml.
+const specificationHelper = "specification"
+
+// UpdateTests rewrites tests calling the Configure and Parameters methods of
+// sources and destinations, which were removed in SDK v0.13. The
+// configuration is parsed into Config() instead, and the parameters are taken
+// from the connector specification in connector.yaml.
+type UpdateTests struct{}
+
+func (u UpdateTests) Description() string {
+	return "Replace Configure() and Parameters() calls in tests"
+}
+
+func (u UpdateTests) Check(fsys FS) (Status, error) {
+	pkgs, err := parsePackages(fsys)
+	if err != nil {
+		return 0, err
+	}
+	kinds, err := u.kinds(context.Background(), fsys, pkgs)
+	if err != nil {
+		return 0, err
+	}
+
+	status := StatusNotApplicable
+	for _, pkg := range pkgs {
+		for _, f := range pkg.tests {
+			calls := u.findCalls(f, pkg.dir, kinds)
+			if slices.ContainsFunc(calls, func(c testCall) bool { return c.resolved }) {
+				return StatusNeedsMigration, nil
+			}
+			ast.Inspect(f.ast, func(n ast.Node) bool {
+				if sel, ok := n.(*ast.SelectorExpr); ok && (sel.Sel.Name == "SourceParams" || sel.Sel.Name == "DestinationParams") {
+					status = StatusAlreadyApplied
+				}
+				return true
+			})
+		}
+	}
+	return status, nil
+}
+
+func (u UpdateTests) Migrate(ctx context.Context, fsys FS) error {
+	pkgs, err := parsePackages(fsys)
+	if err != nil {
+		return err
+	}
+	kinds, err := u.kinds(ctx, fsys, pkgs)
+	if err != nil {
+		return err
+	}
+	report := StepReportFromContext(ctx)
+
+	edits := newGoEdits()
+	for _, pkg := range pkgs {
+		connector := pkg.connectorVar()
+		// helpers are the test packages needing the specification helper,
+		// by name.
+		helpers := make(map[string]bool)
+		for _, f := range pkg.tests {
+			// receivers counts the replaced Parameters calls by the
+			// variable they were called on.
+			receivers := make(map[*ast.Object]int)
+			alias, ok := importName(f.ast, sdkModule)
+			if !ok {
+				alias = "sdk"
+			}
+			for _, call := range u.findCalls(f, pkg.dir, kinds) {
+				pos := f.fset.Position(call.expr.Pos())
+				if !call.resolved {
+					report.Warnf("%s: couldn't tell if %s is called on a source or a destination, migrate it manually", pos, call.method())
+					continue
+				}
+				// Tests of the package declaring the connector use it,
+				// other packages can't import it and parse connector.yaml.
+				spec := connector + ".NewSpecification()"
+				if connector == "" || f.ast.Name.Name != pkg.packageName() {
+					spec = specificationHelper + "()"
+					helpers[f.ast.Name.Name] = true
+				}
+				params := spec + "." + call.plugin.iface + "Params"
+
+				replaceTestCall(f, call.expr, alias, params, edits)
+				if sel := call.expr.Fun.(*ast.SelectorExpr); sel.Sel.Name == "Parameters" {
+					if ident, ok := ast.Unparen(sel.X).(*ast.Ident); ok && ident.Obj != nil && ident.Obj.Kind == ast.Var {
+						receivers[ident.Obj]++
+					}
+				}
+				report.Notef("%s: replaced %s", pos, call.method())
+			}
+			objs := slices.SortedFunc(maps.Keys(receivers), func(a, b *ast.Object) int {
+				return int(a.Pos() - b.Pos())
+			})
+			for _, obj := range objs {
+				if u.uses(f, obj) == receivers[obj] {
+					report.Warnf("%s: %s isn't used anymore after replacing %s.Parameters(), remove it manually", f.fset.Position(obj.Pos()), obj.Name, obj.Name)
+				}
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(helpers)) {
+			u.addHelper(pkg, name, edits)
+		}
+	}
+
+	_, err = edits.write(fsys)
+	return err
+}
+
+// replaceTestCall replaces a call of Configure with parsing the configuration
+// into Config(), or a call of Parameters with params.
+func replaceTestCall(f *goFile, call *ast.CallExpr, alias, params string, edits *goEdits) {
+	sel := call.Fun.(*ast.SelectorExpr)
+	text := params
+	if sel.Sel.Name == "Configure" {
+		edits.addImport(f, sdkModule, alias)
+		text = fmt.Sprintf("%s.Util.ParseConfig(%s, %s, %s.Config(), %s)", alias,
+			f.text(call.Args[0]), f.text(call.Args[1]), f.text(sel.X), params)
+	}
+	edits.add(f, textEdit{start: f.offset(call.Pos()), end: f.offset(call.End()), text: text})
+}
+
+// testCall is a call of Configure or Parameters in a test.
+type testCall struct {
+	expr *ast.CallExpr
+	// resolved is true if the call is made on a source or destination, or
+	// Parameters on a config struct of one.
+	resolved bool
+	plugin   plugin
+}
+
+func (c testCall) method() string {
+	return c.expr.Fun.(*ast.SelectorExpr).Sel.Name + "()"
+}
+
+// kinds returns the plugins of the types, constructors and config structs in
+// each package, by name.
+func (u UpdateTests) kinds(ctx context.Context, fsys FS, pkgs []*goPackage) (map[string]map[string]plugin, error) {
+	kinds := make(map[string]map[string]plugin)
+	for _, pkg := range pkgs {
+		kinds[pkg.dir] = make(map[string]plugin)
+	}
+	for _, p := range []plugin{sourcePlugin, destinationPlugin} {
+		impls, err := p.implementations(ctx, fsys)
+		if err != nil {
+			return nil, err
+		}
+		for _, impl := range impls {
+			kinds[impl.pkg.dir][impl.name] = p
+			if field, _, ok := impl.configField(); ok {
+				if typeName, _, ok := structFieldType(impl.spec, field); ok {
+					kinds[impl.pkg.dir][typeName] = p
+				}
+			}
+		}
+
+		// Constructors, e.g. func NewSource() sdk.Source
+		for _, pkg := range pkgs {
+			for _, f := range pkg.files {
+				alias, ok := importName(f.ast, sdkModule)
+				if !ok {
+					continue
+				}
+				for _, decl := range f.ast.Decls {
+					fn, ok := decl.(*ast.FuncDecl)
+					if !ok || fn.Recv != nil || fn.Type.Results == nil || len(fn.Type.Results.List) != 1 {
+						continue
+					}
+					if isSelector(fn.Type.Results.List[0].Type, alias, p.iface) {
+						kinds[pkg.dir][fn.Name.Name] = p
+					}
+				}
+			}
+		}
+	}
+	return kinds, nil
+}
+
+// findCalls returns the calls of Configure with two arguments and Parameters
+// without arguments in the test file. Acceptance tests are migrated by
+// UpdateAcceptanceTests.
+func (u UpdateTests) findCalls(f *goFile, dir string, kinds map[string]map[string]plugin) []testCall {
+	if isAcceptanceTest(f) {
+		return nil
+	}
+	var calls []testCall
+	ast.Inspect(f.ast, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !(sel.Sel.Name == "Configure" && len(call.Args) == 2 || sel.Sel.Name == "Parameters" && len(call.Args) == 0) {
+			return true
+		}
+		p, ok := u.resolve(sel.X, f, dir, kinds, 0)
+		calls = append(calls, testCall{expr: call, resolved: ok, plugin: p})
+		return true
+	})
+	return calls
+}
+
+// resolve returns the plugin of the value of expr by following it back to a
+// constructor call or a composite literal, e.g. in
+//
+//	src := NewSource()
+//	src.Configure(ctx, cfg)
+func (u UpdateTests) resolve(expr ast.Expr, f *goFile, dir string, kinds map[string]map[string]plugin, depth int) (plugin, bool) {
+	if depth > 10 {
+		return plugin{}, false
+	}
+	depth++
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return u.resolve(e.X, f, dir, kinds, depth)
+	case *ast.StarExpr:
+		return u.resolve(e.X, f, dir, kinds, depth)
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return u.resolve(e.X, f, dir, kinds, depth)
+		}
+	case *ast.CompositeLit:
+		return u.resolve(e.Type, f, dir, kinds, depth)
+	case *ast.CallExpr:
+		return u.resolve(e.Fun, f, dir, kinds, depth)
+	case *ast.SelectorExpr:
+		// source.NewSource in external tests and other packages
+		if ident, ok := e.X.(*ast.Ident); ok && ident.Obj == nil {
+			p, ok := kinds[u.importedDir(f, ident.Name, kinds)][e.Sel.Name]
+			return p, ok
+		}
+	case *ast.Ident:
+		if e.Obj == nil {
+			p, ok := kinds[dir][e.Name]
+			return p, ok
+		}
+		switch decl := e.Obj.Decl.(type) {
+		case *ast.AssignStmt:
+			i := slices.IndexFunc(decl.Lhs, func(lhs ast.Expr) bool { return isIdent(lhs, e.Name) })
+			if i >= 0 && len(decl.Lhs) == len(decl.Rhs) {
+				return u.resolve(decl.Rhs[i], f, dir, kinds, depth)
+			}
+		case *ast.ValueSpec:
+			i := slices.IndexFunc(decl.Names, func(name *ast.Ident) bool { return name.Name == e.Name })
+			if i >= 0 && len(decl.Names) == len(decl.Values) {
+				return u.resolve(decl.Values[i], f, dir, kinds, depth)
+			}
+			if decl.Type != nil {
+				return u.resolve(decl.Type, f, dir, kinds, depth)
+			}
+		case *ast.Field:
+			return u.resolve(decl.Type, f, dir, kinds, depth)
+		}
+	}
+	return plugin{}, false
+}
+
+// uses returns the number of uses of the variable in the file, not counting
+// its declaration.
+func (u UpdateTests) uses(f *goFile, obj *ast.Object) int {
+	var n int
+	ast.Inspect(f.ast, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && ident.Obj == obj && ident.Pos() != obj.Pos() {
+			n++
+		}
+		return true
+	})
+	return n
+}
+
+// importedDir returns the directory of the connector package imported as name
+// in the file, or an empty string if it's not a package of the connector.
+func (u UpdateTests) importedDir(f *goFile, name string, kinds map[string]map[string]plugin) string {
+	for _, imp := range f.ast.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		if n, _ := importName(f.ast, importPath); n != name {
+			continue
+		}
+		for dir := range kinds {
+			if dir != "." && strings.HasSuffix(importPath, "/"+dir) {
+				return dir
+			}
+		}
+	}
+	return ""
+}
+
+// connectorVar returns the name of the package-level variable holding the
+// sdk.Connector, or an empty string if the package doesn't declare it.
+func (p *goPackage) connectorVar() string {
+	name, _, _ := p.connectorDecl()
+	return name
+}
+
+// connectorDecl returns the name of the package-level variable holding the
+// sdk.Connector, together with the file declaring it and its literal.
+func (p *goPackage) connectorDecl() (string, *goFile, *ast.CompositeLit) {
+	for _, f := range p.files {
+		alias, ok := importName(f.ast, sdkModule)
+		if !ok {
+			continue
+		}
+		for _, decl := range f.ast.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, value := range vs.Values {
+					if lit, ok := value.(*ast.CompositeLit); ok && isSelector(lit.Type, alias, "Connector") && i < len(vs.Names) {
+						return vs.Names[i].Name, f, lit
+					}
+				}
+			}
+		}
+	}
+	return "", nil, nil
+}
+
+// packageName returns the name of the package, without _test for external
+// tests.
+func (p *goPackage) packageName() string {
+	if len(p.files) > 0 {
+		return p.files[0].ast.Name.Name
+	}
+	if len(p.tests) > 0 {
+		return strings.TrimSuffix(p.tests[0].ast.Name.Name, "_test")
+	}
+	return ""
+}
+
+// addHelper adds the function returning the specification from
+// connector.yaml to the first test file of the named test package, unless
+// it's already declared. External tests are a separate package, so they get
+// their own helper.
+func (u UpdateTests) addHelper(pkg *goPackage, name string, edits *goEdits) {
+	var f *goFile
+	for _, file := range slices.Concat(pkg.files, pkg.tests) {
+		if file.ast.Name.Name != name {
+			continue
+		}
+		if file.ast.Scope.Lookup(specificationHelper) != nil {
+			return
+		}
+		if f == nil && strings.HasSuffix(file.name, "_test.go") {
+			f = file
+		}
+	}
+
+	// connector.yaml is in the root of the connector.
+	var up []string
+	if pkg.dir != "." {
+		for range strings.Split(pkg.dir, "/") {
+			up = append(up, "..")
+		}
+	}
+	specPath := path.Join(append(up, "connector.yaml")...)
+
+	alias, ok := importName(f.ast, sdkModule)
+	if !ok {
+		alias = "sdk"
+	}
+	edits.addImport(f, sdkModule, alias)
+	edits.addImport(f, "context", "")
+	edits.addImport(f, "os", "")
+
+	helper := fmt.Sprintf(`
+
+// %[1]s returns the connector specification from %[2]s.
+func %[1]s() %[3]s.Specification {
+	raw, err := os.ReadFile(%[2]q)
+	if err != nil {
+		panic(err)
+	}
+	spec, err := %[3]s.ParseYAMLSpecification(context.Background(), string(raw), "")
+	if err != nil {
+		panic(err)
+	}
+	return spec
+}
+`, specificationHelper, specPath, alias)
+	end := len(f.content)
+	edits.add(f, textEdit{start: end, end: end, text: helper})
+}
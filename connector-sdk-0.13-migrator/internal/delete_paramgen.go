@@ -15,68 +15,115 @@
 package internal
 
 import (
+	"bytes"
+	"context"
 	"fmt"
-	"os"
-	"path/filepath"
-	"regexp"
+	"go/ast"
 	"strings"
 )
 
+const (
+	paramgenGeneratedMarker = "// Code generated by paramgen. DO NOT EDIT."
+	paramgenDirective       = "//go:generate paramgen"
+)
+
 type DeleteParamGen struct {
 }
 
-func (d DeleteParamGen) Migrate(workingDir string) error {
-	// Walk through the directory recursively
-	err := filepath.Walk(workingDir, func(path string, info os.FileInfo, err error) error {
-		// Check if there was an error accessing the path
-		if err != nil {
-			return err
-		}
+func (d DeleteParamGen) Description() string {
+	return "Remove paramgen generated code and directives"
+}
 
-		// Skip directories
-		if info.IsDir() {
-			return nil
+func (d DeleteParamGen) Check(fsys FS) (Status, error) {
+	files, err := d.files(fsys)
+	if err != nil {
+		return 0, err
+	}
+	// Once paramgen is gone, the specification is generated by specgen.
+	status := StatusNotApplicable
+	for _, f := range files {
+		if d.generated(f) || len(d.directives(f)) > 0 {
+			return StatusNeedsMigration, nil
 		}
-
-		// Open the file
-		file, err := os.Open(path)
-		if err != nil {
-			return err
-		}
-		defer file.Close()
-
-		// Read entire file into string
-		content, err := os.ReadFile(path)
-		if err != nil {
-			return err
+		if hasComment(f.ast, specgenDirective) {
+			status = StatusAlreadyApplied
 		}
+	}
+	return status, nil
+}
 
-		if strings.Contains(string(content), "// Code generated by paramgen. DO NOT EDIT.") {
-			fmt.Printf("deleting %s\n", path)
+func (d DeleteParamGen) Migrate(ctx context.Context, fsys FS) error {
+	files, err := d.files(fsys)
+	if err != nil {
+		return err
+	}
 
-			err := os.Remove(file.Name())
-			if err != nil {
-				return fmt.Errorf("removing file %s: %w", file, err)
+	edits := newGoEdits()
+	for _, f := range files {
+		if d.generated(f) {
+			fmt.Printf("deleting %s\n", f.name)
+			if err := fsys.Remove(f.name); err != nil {
+				return fmt.Errorf("removing file %s: %w", f.name, err)
 			}
-			return nil
+			continue
 		}
 
-		if strings.Contains(string(content), "//go:generate paramgen") {
-			fmt.Printf("updating %s (removing paramgen)\n", path)
-			// Find and replace the generate line (and its newline)
-			regex := regexp.MustCompile(`(?m)^[\t ]*//go:generate paramgen.*\n`)
-			newContent := regex.ReplaceAllString(string(content), "")
-
-			// Write back to file
-			return os.WriteFile(path, []byte(newContent), 0644)
+		directives := d.directives(f)
+		if len(directives) == 0 {
+			continue
 		}
+		fmt.Printf("updating %s (removing paramgen)\n", f.name)
+		for _, c := range directives {
+			edits.add(f, d.removeLine(f, c))
+		}
+	}
 
-		return nil
-	})
+	_, err = edits.write(fsys)
+	return err
+}
 
+// files returns the Go files of all packages, including tests.
+func (d DeleteParamGen) files(fsys FS) ([]*goFile, error) {
+	pkgs, err := parsePackages(fsys)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	var files []*goFile
+	for _, pkg := range pkgs {
+		files = append(files, pkg.files...)
+		files = append(files, pkg.tests...)
+	}
+	return files, nil
+}
+
+// generated reports whether the file was generated by paramgen.
+func (d DeleteParamGen) generated(f *goFile) bool {
+	return bytes.Contains(f.content, []byte(paramgenGeneratedMarker))
+}
 
-	return nil
+// directives returns the paramgen directives in the file.
+func (d DeleteParamGen) directives(f *goFile) []*ast.Comment {
+	var directives []*ast.Comment
+	for _, group := range f.ast.Comments {
+		for _, c := range group.List {
+			if c.Text == paramgenDirective || strings.HasPrefix(c.Text, paramgenDirective+" ") {
+				directives = append(directives, c)
+			}
+		}
+	}
+	return directives
+}
+
+// removeLine returns the edit removing the comment. If it's the only thing
+// on its line, the whole line is removed.
+func (d DeleteParamGen) removeLine(f *goFile, c *ast.Comment) textEdit {
+	start, end := f.offset(c.Pos()), f.offset(c.End())
+	lineStart := bytes.LastIndexByte(f.content[:start], '\n') + 1
+	if len(bytes.TrimSpace(f.content[lineStart:start])) > 0 {
+		return textEdit{start: start, end: end}
+	}
+	if end < len(f.content) && f.content[end] == '\n' {
+		end++
+	}
+	return textEdit{start: lineStart, end: end}
 }
@@ -0,0 +1,68 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"testing"
+)
+
+func TestDeleteParamGenCheck(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  Status
+	}{{
+		name: "paramgen directive",
+		files: map[string]string{
+			"source.go": "package source\n\n" + paramgenDirective + " Config\n",
+		},
+		want: StatusNeedsMigration,
+	}, {
+		name: "paramgen output",
+		files: map[string]string{
+			"paramgen_src.go": paramgenGeneratedMarker + "\n\npackage source\n",
+		},
+		want: StatusNeedsMigration,
+	}, {
+		name: "specgen directive",
+		files: map[string]string{
+			"connector.go": specgenDirective + "\n\npackage example\n",
+		},
+		want: StatusAlreadyApplied,
+	}, {
+		name: "no paramgen",
+		files: map[string]string{
+			"connector.go": "package example\n",
+		},
+		want: StatusNotApplicable,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFS()
+			for name, content := range tc.files {
+				if err := fsys.WriteFile(name, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := DeleteParamGen{}.Check(fsys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
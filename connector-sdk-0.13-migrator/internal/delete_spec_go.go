@@ -15,17 +15,31 @@
 package internal
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 )
 
 type DeleteSpecGo struct {
 }
 
-func (d DeleteSpecGo) Migrate(workingDir string) error {
-	path := filepath.Join(workingDir, "spec.go")
-	err := os.Remove(path)
+func (d DeleteSpecGo) Description() string {
+	return "Remove spec.go"
+}
+
+func (d DeleteSpecGo) Check(fsys FS) (Status, error) {
+	exists, err := fileExists(fsys, "spec.go")
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return StatusAlreadyApplied, nil
+	}
+	return StatusNeedsMigration, nil
+}
+
+func (d DeleteSpecGo) Migrate(ctx context.Context, fsys FS) error {
+	path := "spec.go"
+	err := fsys.Remove(path)
 	if err != nil {
 		return fmt.Errorf("failed removing %s: %s", path, err)
 	}
@@ -0,0 +1,274 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each hunk.
+const diffContext = 3
+
+type diffOpKind byte
+
+const (
+	opEqual  diffOpKind = ' '
+	opDelete diffOpKind = '-'
+	opInsert diffOpKind = '+'
+)
+
+// diffOp is a single line in a diff. Lines keep their trailing newline (the
+// last line of a file might not have one).
+type diffOp struct {
+	kind diffOpKind
+	line string
+}
+
+// hunk is a group of changed lines surrounded by context lines.
+type hunk struct {
+	oldStart, oldLines int
+	newStart, newLines int
+	ops                []diffOp
+}
+
+func (h hunk) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(h.oldStart, h.oldLines), hunkRange(h.newStart, h.newLines))
+	for _, op := range h.ops {
+		sb.WriteByte(byte(op.kind))
+		sb.WriteString(op.line)
+		if !strings.HasSuffix(op.line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+	return sb.String()
+}
+
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// unifiedDiff returns the diff between old and new in the unified format.
+// An empty string is returned if the contents are equal.
+func unifiedDiff(oldName, newName string, old, new []byte) string {
+	hunks := diffHunks(splitLines(string(old)), splitLines(string(new)), diffContext)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks {
+		sb.WriteString(h.String())
+	}
+	return sb.String()
+}
+
+// splitLines splits s into lines, keeping the line terminators.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffHunks groups the line diff of a and b into hunks with the given number
+// of context lines.
+func diffHunks(a, b []string, context int) []hunk {
+	ops := diffLines(a, b)
+
+	var hunks []hunk
+	var oldLine, newLine int // lines consumed so far
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		// Found a change, include up to context preceding lines.
+		start := i
+		for start > 0 && i-start < context && ops[start-1].kind == opEqual {
+			start--
+		}
+		h := hunk{
+			oldStart: oldLine - (i - start) + 1,
+			newStart: newLine - (i - start) + 1,
+		}
+
+		// Extend the hunk until we reach more than 2*context equal lines in a
+		// row or the end of the diff.
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == opEqual {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end = min(end+context, run)
+				break
+			}
+			end = run
+		}
+
+		h.ops = ops[start:end]
+		for _, op := range h.ops {
+			if op.kind != opInsert {
+				h.oldLines++
+			}
+			if op.kind != opDelete {
+				h.newLines++
+			}
+		}
+		// An empty range starts at the line before the change.
+		if h.oldLines == 0 {
+			h.oldStart--
+		}
+		if h.newLines == 0 {
+			h.newStart--
+		}
+		hunks = append(hunks, h)
+
+		for _, op := range ops[i:end] {
+			if op.kind != opInsert {
+				oldLine++
+			}
+			if op.kind != opDelete {
+				newLine++
+			}
+		}
+		i = end
+	}
+
+	return hunks
+}
+
+// diffLines computes the shortest edit script that transforms a into b using
+// the Myers diff algorithm.
+func diffLines(a, b []string) []diffOp {
+	// Lines shared at the start and end don't need to go through the search.
+	var prefix, suffix int
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{kind: opEqual, line: line})
+	}
+	ops = append(ops, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{kind: opEqual, line: line})
+	}
+	return ops
+}
+
+// myersRound stores the furthest reaching x for diagonals -d-1 to d+1 before
+// round d of the search.
+type myersRound struct {
+	d int
+	v []int
+}
+
+func (r myersRound) x(k int) int {
+	return r.v[k+r.d+1]
+}
+
+func myers(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD == 0 {
+		return nil
+	}
+
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace []myersRound
+
+search:
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, myersRound{d: d, v: append([]int(nil), v[offset-d-1:offset+d+2]...)})
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Backtrack through the trace to build the edit script in reverse.
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		round := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && round.x(k-1) < round.x(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := round.x(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{kind: opEqual, line: a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, diffOp{kind: opInsert, line: b[prevY]})
+			} else {
+				ops = append(ops, diffOp{kind: opDelete, line: a[prevX]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
@@ -0,0 +1,76 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	testCases := []struct {
+		name     string
+		old, new string
+		want     string
+	}{{
+		name: "equal",
+		old:  "a\nb\n",
+		new:  "a\nb\n",
+		want: "",
+	}, {
+		name: "empty",
+		want: "",
+	}, {
+		name: "created",
+		new:  "a\nb\n",
+		want: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+	}, {
+		name: "deleted",
+		old:  "a\nb\n",
+		want: "--- old\n+++ new\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+	}, {
+		name: "insert only",
+		old:  "1\n2\n3\n4\n5\n6\n7\n8\n",
+		new:  "1\n2\n3\n4\nx\n5\n6\n7\n8\n",
+		want: "--- old\n+++ new\n@@ -2,6 +2,7 @@\n 2\n 3\n 4\n+x\n 5\n 6\n 7\n",
+	}, {
+		name: "delete only",
+		old:  "1\n2\n3\n4\n5\n",
+		new:  "1\n2\n4\n5\n",
+		want: "--- old\n+++ new\n@@ -1,5 +1,4 @@\n 1\n 2\n-3\n 4\n 5\n",
+	}, {
+		name: "separate hunks",
+		old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+		new:  "x\n2\n3\n4\n5\n6\n7\n8\n9\ny\n",
+		want: "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+y\n",
+	}, {
+		name: "no trailing newline",
+		old:  "a\nb",
+		new:  "a\nc",
+		want: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+	}, {
+		name: "trailing newline added",
+		old:  "a",
+		new:  "a\n",
+		want: "--- old\n+++ new\n@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+a\n",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := unifiedDiff("old", "new", []byte(tc.old), []byte(tc.new))
+			if got != tc.want {
+				t.Errorf("unexpected diff:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// textEdit replaces the bytes from start to end with text. The offsets refer
+// to the original content, so edits don't depend on each other.
+type textEdit struct {
+	start, end int
+	text       string
+}
+
+// applyEdits applies the edits to content in one pass. Insertions at the same
+// position are applied in the given order, before a replacement starting
+// there. Overlapping edits are a conflict, as the result would depend on the
+// order they're applied in.
+func applyEdits(content []byte, edits []textEdit) ([]byte, error) {
+	edits = slices.Clone(edits)
+	slices.SortStableFunc(edits, func(a, b textEdit) int {
+		if a.start != b.start {
+			return a.start - b.start
+		}
+		return (a.end - a.start) - (b.end - b.start)
+	})
+
+	var out []byte
+	last := 0
+	for i, e := range edits {
+		if e.start < 0 || e.end < e.start || e.end > len(content) {
+			return nil, fmt.Errorf("invalid edit of offsets %d-%d in %d bytes", e.start, e.end, len(content))
+		}
+		if e.start < last {
+			prev := edits[i-1]
+			return nil, fmt.Errorf("conflicting edits of offsets %d-%d and %d-%d", prev.start, prev.end, e.start, e.end)
+		}
+		out = append(out, content[last:e.start]...)
+		out = append(out, e.text...)
+		last = e.end
+	}
+	return append(out, content[last:]...), nil
+}
+
+// goEdits collects the edits of Go files against their original content, so
+// that each file is changed and formatted once, regardless of how many
+// migration steps edit it.
+type goEdits struct {
+	edits map[*goFile][]textEdit
+	// imports are the imports needed by the edited files, as names by path.
+	// The name is empty if the package is imported without a name.
+	imports map[*goFile]map[string]string
+}
+
+func newGoEdits() *goEdits {
+	return &goEdits{
+		edits:   make(map[*goFile][]textEdit),
+		imports: make(map[*goFile]map[string]string),
+	}
+}
+
+func (e *goEdits) add(f *goFile, edit textEdit) {
+	e.edits[f] = append(e.edits[f], edit)
+}
+
+// remove removes the declaration with its doc comment.
+func (e *goEdits) remove(pkg *goPackage, f *goFile, decl *ast.FuncDecl) {
+	start := decl.Pos()
+	if decl.Doc != nil {
+		start = decl.Doc.Pos()
+	}
+	e.add(f, textEdit{start: pkg.offset(start), end: pkg.offset(decl.End())})
+}
+
+// addImport makes sure that the file imports the package.
+func (e *goEdits) addImport(f *goFile, importPath, name string) {
+	if e.imports[f] == nil {
+		e.imports[f] = make(map[string]string)
+	}
+	e.imports[f][importPath] = name
+}
+
+// apply returns the formatted content of the edited file. Imports that are
+// needed are added, imports that aren't used anymore after the edits are
+// removed.
+func (e *goEdits) apply(f *goFile) ([]byte, error) {
+	edits := e.edits[f]
+	missing := make(map[string]string)
+	for importPath, name := range e.imports[f] {
+		if _, ok := importName(f.ast, importPath); !ok {
+			missing[importPath] = name
+		}
+	}
+	edits = append(edits, importEdits(f.ast, f.content, f.offset, missing)...)
+
+	content, err := applyEdits(f.content, edits)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, f.name, content, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	for _, imp := range f.ast.Imports {
+		importPath, _ := strconv.Unquote(imp.Path.Value)
+		name, _ := importName(f.ast, importPath)
+		if packageUses(f.ast, name) > 0 && packageUses(file, name) == 0 {
+			var explicit string
+			if imp.Name != nil {
+				explicit = imp.Name.Name
+			}
+			astutil.DeleteNamedImport(fset, file, explicit, importPath)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// write applies the edits and writes the edited files, in the order of
+// their names. It returns the names of the written files.
+func (e *goEdits) write(fsys FS) ([]string, error) {
+	edited := maps.Clone(e.imports)
+	for f := range e.edits {
+		edited[f] = nil
+	}
+	files := slices.SortedFunc(maps.Keys(edited), func(a, b *goFile) int {
+		return strings.Compare(a.name, b.name)
+	})
+	var names []string
+	for _, f := range files {
+		updated, err := e.apply(f)
+		if err != nil {
+			return nil, fmt.Errorf("error applying edits to %s: %w", f.name, err)
+		}
+		perm := fs.FileMode(0644)
+		if info, err := fsys.Stat(f.name); err == nil {
+			perm = info.Mode().Perm()
+		}
+		if err := fsys.WriteFile(f.name, updated, perm); err != nil {
+			return nil, fmt.Errorf("error writing modified file %s: %w", f.name, err)
+		}
+		names = append(names, f.name)
+	}
+	return names, nil
+}
+
+// packageUses counts the references to the imported package with the given
+// name in file.
+func packageUses(file *ast.File, name string) int {
+	n := 0
+	ast.Inspect(file, func(node ast.Node) bool {
+		sel, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == name && ident.Obj == nil {
+			n++
+		}
+		return true
+	})
+	return n
+}
+
+// importEdits returns the edits adding the imports, given as names by path,
+// to the first import declaration, which is turned into a grouped
+// declaration if needed. Standard library imports are usually grouped first,
+// so they join that group or start a new one. Other imports join the first
+// group with imports that aren't from the standard library or start a new
+// group after the standard library imports.
+func importEdits(file *ast.File, content []byte, offset func(token.Pos) int, imports map[string]string) []textEdit {
+	var std, other []string
+	for _, importPath := range slices.Sorted(maps.Keys(imports)) {
+		spec := strconv.Quote(importPath)
+		if name := imports[importPath]; name != "" {
+			spec = name + " " + spec
+		}
+		if isStdPath(importPath) {
+			std = append(std, spec)
+		} else {
+			other = append(other, spec)
+		}
+	}
+	if len(std)+len(other) == 0 {
+		return nil
+	}
+
+	var decl *ast.GenDecl
+	for _, d := range file.Decls {
+		if gen, ok := d.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			decl = gen
+			break
+		}
+	}
+
+	group := func(specs []string) string {
+		return strings.Join(specs, "\n\t")
+	}
+	switch {
+	case decl == nil:
+		// No imports, add a declaration after the package clause.
+		var groups []string
+		for _, specs := range [][]string{std, other} {
+			if len(specs) > 0 {
+				groups = append(groups, "\t"+group(specs))
+			}
+		}
+		end := offset(file.Name.End())
+		return []textEdit{{start: end, end: end, text: "\n\nimport (\n" + strings.Join(groups, "\n\n") + "\n)"}}
+	case !decl.Lparen.IsValid():
+		// import sdk "github.com/conduitio/conduit-connector-sdk"
+		existing := decl.Specs[0].(*ast.ImportSpec)
+		start, end := offset(existing.Pos()), offset(existing.End())
+		if isStdImport(existing) {
+			std = append(std, string(content[start:end]))
+		} else {
+			other = append(other, string(content[start:end]))
+		}
+		var groups []string
+		for _, specs := range [][]string{std, other} {
+			if len(specs) > 0 {
+				groups = append(groups, "\t"+group(specs))
+			}
+		}
+		return []textEdit{{start: offset(decl.Pos()), end: end, text: "import (\n" + strings.Join(groups, "\n\n") + "\n)"}}
+	}
+
+	var edits []textEdit
+	firstOther := -1
+	for i, spec := range decl.Specs {
+		if !isStdImport(spec.(*ast.ImportSpec)) {
+			firstOther = i
+			break
+		}
+	}
+	if len(std) > 0 {
+		text := "\n\t" + group(std)
+		if firstOther == 0 {
+			text += "\n"
+		}
+		lparen := offset(decl.Lparen) + 1
+		edits = append(edits, textEdit{start: lparen, end: lparen, text: text})
+	}
+	if len(other) > 0 {
+		switch {
+		case firstOther >= 0:
+			pos := offset(decl.Specs[firstOther].Pos())
+			edits = append(edits, textEdit{start: pos, end: pos, text: group(other) + "\n\t"})
+		case len(decl.Specs) > 0:
+			end := offset(decl.Specs[len(decl.Specs)-1].End())
+			edits = append(edits, textEdit{start: end, end: end, text: "\n\n\t" + group(other)})
+		default:
+			lparen := offset(decl.Lparen) + 1
+			edits = append(edits, textEdit{start: lparen, end: lparen, text: "\n\t" + group(other)})
+		}
+	}
+	return edits
+}
+
+// isStdImport reports whether the import is from the standard library.
+func isStdImport(imp *ast.ImportSpec) bool {
+	p, _ := strconv.Unquote(imp.Path.Value)
+	return isStdPath(p)
+}
+
+// isStdPath reports whether the import path is from the standard library.
+func isStdPath(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
@@ -0,0 +1,100 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"go/token"
+	"io/fs"
+	"testing"
+)
+
+func TestApplyEdits(t *testing.T) {
+	testCases := []struct {
+		name    string
+		edits   []textEdit
+		want    string
+		wantErr bool
+	}{{
+		name:  "edits in any order",
+		edits: []textEdit{{start: 6, end: 11, text: "there"}, {start: 0, end: 5, text: "bye"}},
+		want:  "bye there",
+	}, {
+		name:  "insertions at the same position keep their order",
+		edits: []textEdit{{start: 5, end: 5, text: ","}, {start: 5, end: 5, text: " you"}},
+		want:  "hello, you world",
+	}, {
+		name:  "insertion before a replacement at the same position",
+		edits: []textEdit{{start: 6, end: 11, text: "there"}, {start: 6, end: 6, text: "out "}},
+		want:  "hello out there",
+	}, {
+		name:  "insertion at the end of a replacement",
+		edits: []textEdit{{start: 0, end: 5, text: "bye"}, {start: 5, end: 5, text: ","}},
+		want:  "bye, world",
+	}, {
+		name:    "overlapping replacements",
+		edits:   []textEdit{{start: 0, end: 7, text: "bye"}, {start: 6, end: 11, text: "there"}},
+		wantErr: true,
+	}, {
+		name:    "insertion inside a replacement",
+		edits:   []textEdit{{start: 0, end: 5, text: "bye"}, {start: 2, end: 2, text: "!"}},
+		wantErr: true,
+	}, {
+		name:    "edit out of range",
+		edits:   []textEdit{{start: 6, end: 20, text: "there"}},
+		wantErr: true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := applyEdits([]byte("hello world"), tc.edits)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGoEditsWriteKeepsPermissions(t *testing.T) {
+	fsys := NewMemFS()
+	if err := fsys.WriteFile("gen.go", []byte("package gen\n\nvar x = 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parseGoFile(fsys, token.NewFileSet(), "gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edits := newGoEdits()
+	edits.add(f, textEdit{start: len("package gen\n\nvar x = "), end: len("package gen\n\nvar x = 1"), text: "2"})
+	if _, err := edits.write(fsys); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := fsys.Stat("gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != 0755 {
+		t.Fatalf("got mode %v, want %v", got, fs.FileMode(0755))
+	}
+}
@@ -0,0 +1,430 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FS is the file system migrators read from and write to. Paths are
+// slash-separated and relative to the root of the connector.
+type FS interface {
+	ReadFile(name string) ([]byte, error)
+	// WriteFile writes data to the named file, creating it and any missing
+	// parent directories if necessary.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	// Remove removes the named file or empty directory. File systems without
+	// real directories (MemFS) only remove files.
+	Remove(name string) error
+	Stat(name string) (fs.FileInfo, error)
+	// WalkDir walks the file tree rooted at root in lexical order, same as
+	// fs.WalkDir.
+	WalkDir(root string, fn fs.WalkDirFunc) error
+}
+
+// OSFS is an FS backed by a directory on disk.
+type OSFS struct {
+	root string
+}
+
+func NewOSFS(root string) OSFS {
+	return OSFS{root: root}
+}
+
+// Dir returns the directory the file system is rooted at.
+func (o OSFS) Dir() string {
+	return o.root
+}
+
+func (o OSFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(o.path(name))
+}
+
+func (o OSFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	p := o.path(name)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, perm)
+}
+
+func (o OSFS) Remove(name string) error {
+	return os.Remove(o.path(name))
+}
+
+func (o OSFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(o.path(name))
+}
+
+func (o OSFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	return filepath.WalkDir(o.path(root), func(p string, d fs.DirEntry, err error) error {
+		rel, relErr := filepath.Rel(o.root, p)
+		if relErr != nil {
+			return relErr
+		}
+		return fn(filepath.ToSlash(rel), d, err)
+	})
+}
+
+func (o OSFS) path(name string) string {
+	return filepath.Join(o.root, filepath.FromSlash(path.Clean(name)))
+}
+
+// MemFS is an FS that keeps all files in memory. Directories exist implicitly
+// as long as they contain a file.
+type MemFS struct {
+	files map[string]*memFile
+}
+
+type memFile struct {
+	data []byte
+	perm fs.FileMode
+}
+
+func NewMemFS() *MemFS {
+	return &MemFS{files: make(map[string]*memFile)}
+}
+
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	f, ok := m.files[path.Clean(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return bytes.Clone(f.data), nil
+}
+
+func (m *MemFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	name = path.Clean(name)
+	if m.isDir(name) {
+		return &fs.PathError{Op: "write", Path: name, Err: errIsDir}
+	}
+	m.files[name] = &memFile{data: bytes.Clone(data), perm: perm}
+	return nil
+}
+
+func (m *MemFS) Remove(name string) error {
+	name = path.Clean(name)
+	if _, ok := m.files[name]; !ok {
+		if m.isDir(name) {
+			return &fs.PathError{Op: "remove", Path: name, Err: errIsDir}
+		}
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(m.files, name)
+	return nil
+}
+
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	name = path.Clean(name)
+	if f, ok := m.files[name]; ok {
+		return fileInfo{name: path.Base(name), size: int64(len(f.data)), mode: f.perm}, nil
+	}
+	if m.isDir(name) {
+		return dirInfo(name), nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+func (m *MemFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	root = path.Clean(root)
+	entries := make(map[string]fs.DirEntry)
+	for p, f := range m.files {
+		if inDir(p, root) {
+			addWalkEntry(entries, root, p, fileInfo{name: path.Base(p), size: int64(len(f.data)), mode: f.perm})
+		}
+	}
+	if len(entries) == 0 {
+		return fn(root, nil, &fs.PathError{Op: "walk", Path: root, Err: fs.ErrNotExist})
+	}
+	return walkEntries(entries, fn)
+}
+
+func (m *MemFS) isDir(name string) bool {
+	for p := range m.files {
+		if name != p && inDir(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+var errIsDir = errors.New("is a directory")
+
+// inDir reports whether the file p is dir or inside of dir.
+func inDir(p, dir string) bool {
+	return dir == "." || p == dir || strings.HasPrefix(p, dir+"/")
+}
+
+// addWalkEntry adds the file p and all its parent directories up to root to
+// entries.
+func addWalkEntry(entries map[string]fs.DirEntry, root, p string, info fs.FileInfo) {
+	entries[p] = fs.FileInfoToDirEntry(info)
+	for dir := p; dir != root; {
+		dir = path.Dir(dir)
+		if _, ok := entries[dir]; !ok {
+			entries[dir] = fs.FileInfoToDirEntry(dirInfo(dir))
+		}
+	}
+}
+
+// walkEntries calls fn for every entry in the same order as fs.WalkDir would.
+func walkEntries(entries map[string]fs.DirEntry, fn fs.WalkDirFunc) error {
+	paths := make([]string, 0, len(entries))
+	for p := range entries {
+		paths = append(paths, p)
+	}
+	// Compare element by element so that a directory is followed by its
+	// contents before any sibling that sorts after the separator.
+	slices.SortFunc(paths, func(a, b string) int {
+		switch {
+		case a == b:
+			return 0
+		case a == ".":
+			return -1
+		case b == ".":
+			return 1
+		}
+		return slices.Compare(strings.Split(a, "/"), strings.Split(b, "/"))
+	})
+
+	var skipDir string
+	for _, p := range paths {
+		if skipDir != "" && inDir(p, skipDir) {
+			continue
+		}
+		skipDir = ""
+
+		d := entries[p]
+		err := fn(p, d, nil)
+		switch {
+		case err == fs.SkipAll:
+			return nil
+		case err == fs.SkipDir && d.IsDir() && p != ".":
+			skipDir = p
+		case err == fs.SkipDir && (d.IsDir() || path.Dir(p) == "."):
+			return nil
+		case err == fs.SkipDir:
+			// Skip the remaining files in the parent directory.
+			skipDir = path.Dir(p)
+		case err != nil:
+			return err
+		}
+	}
+	return nil
+}
+
+// copyToDir copies all regular files in fsys to dir on disk and returns
+// their paths. The .git directory is skipped.
+func copyToDir(fsys FS, dir string) (map[string]bool, error) {
+	copied := make(map[string]bool)
+	err := fsys.WalkDir(".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return fs.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dir, filepath.FromSlash(p)), 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := fsys.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		copied[p] = true
+		return os.WriteFile(filepath.Join(dir, filepath.FromSlash(p)), data, info.Mode().Perm())
+	})
+	return copied, err
+}
+
+// syncFromDir applies the differences between dir on disk and fsys to fsys.
+// It's the counterpart of copyToDir, only the copied files are removed from
+// fsys if they're gone from dir.
+func syncFromDir(fsys FS, dir string, copied map[string]bool) error {
+	seen := make(map[string]bool)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		seen[rel] = true
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		old, err := fsys.ReadFile(rel)
+		if err == nil && bytes.Equal(old, data) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fsys.WriteFile(rel, data, info.Mode().Perm())
+	})
+	if err != nil {
+		return err
+	}
+
+	// Copied files that are gone from dir were removed.
+	var removed []string
+	for p := range copied {
+		if !seen[p] {
+			removed = append(removed, p)
+		}
+	}
+	slices.Sort(removed)
+	for _, p := range removed {
+		if err := fsys.Remove(p); err != nil {
+			return fmt.Errorf("failed removing %s: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// copiedGoMod is a go.mod copied to another directory, whose relative
+// replace directives are resolved against the original directory while the
+// copy is used.
+type copiedGoMod struct {
+	fsys     FS
+	original []byte
+	resolved []byte
+	paths    map[string]string
+}
+
+// resolveReplaces resolves the relative replace directives of the go.mod in
+// the module directory dir of the connector copied from fsys to tmpDir, so
+// they don't point outside of the copy. Nil is returned if there's nothing to
+// resolve.
+func resolveReplaces(fsys FS, dir, tmpDir string) (*copiedGoMod, error) {
+	root, ok := rootDir(fsys)
+	if !ok {
+		return nil, nil
+	}
+	root, err := filepath.Abs(filepath.Join(root, filepath.FromSlash(dir)))
+	if err != nil {
+		return nil, err
+	}
+	tmpFS := NewOSFS(filepath.Join(tmpDir, filepath.FromSlash(dir)))
+	original, err := tmpFS.ReadFile("go.mod")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	mod, err := ReadGoMod(tmpFS, "go.mod")
+	if err != nil {
+		return nil, err
+	}
+	paths, err := mod.ResolveReplaces(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve replace directives: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	if err := mod.Write(tmpFS); err != nil {
+		return nil, err
+	}
+	resolved, err := tmpFS.ReadFile("go.mod")
+	if err != nil {
+		return nil, err
+	}
+	return &copiedGoMod{fsys: tmpFS, original: original, resolved: resolved, paths: paths}, nil
+}
+
+// restore sets the replace directives back to the original paths, keeping
+// other changes of the go.mod.
+func (c *copiedGoMod) restore() error {
+	current, err := c.fsys.ReadFile("go.mod")
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(current, c.resolved) {
+		return c.fsys.WriteFile("go.mod", c.original, 0644)
+	}
+	mod, err := ReadGoMod(c.fsys, "go.mod")
+	if err != nil {
+		return err
+	}
+	if err := mod.RestoreReplaces(c.paths); err != nil {
+		return fmt.Errorf("failed to restore replace directives: %w", err)
+	}
+	return mod.Write(c.fsys)
+}
+
+// osDir returns the directory on disk fsys writes to directly, i.e. without
+// an Overlay keeping the changes in memory.
+func osDir(fsys FS) (string, bool) {
+	switch fsys := fsys.(type) {
+	case OSFS:
+		return fsys.Dir(), true
+	case *Snapshot:
+		return osDir(fsys.FS)
+	}
+	return "", false
+}
+
+// rootDir returns the directory on disk the files of fsys come from, also if
+// the changes are kept in memory by an Overlay.
+func rootDir(fsys FS) (string, bool) {
+	switch fsys := fsys.(type) {
+	case *Overlay:
+		return rootDir(fsys.base)
+	case *Snapshot:
+		return rootDir(fsys.FS)
+	}
+	return osDir(fsys)
+}
+
+// fileInfo describes a file or directory that only exists in memory.
+type fileInfo struct {
+	name string
+	size int64
+	mode fs.FileMode
+}
+
+func dirInfo(name string) fileInfo {
+	return fileInfo{name: path.Base(name), mode: fs.ModeDir | 0755}
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return fi.size }
+func (fi fileInfo) Mode() fs.FileMode  { return fi.mode }
+func (fi fileInfo) ModTime() time.Time { return time.Time{} }
+func (fi fileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi fileInfo) Sys() any           { return nil }
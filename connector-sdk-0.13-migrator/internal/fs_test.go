@@ -0,0 +1,126 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunCommandInPlace(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	// The command sees files outside of the connector, e.g. the target of a
+	// relative replace directive.
+	parent := t.TempDir()
+	if err := os.WriteFile(filepath.Join(parent, "outside"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(parent, "connector")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := NewSnapshot(NewOSFS(dir))
+	err := runCommand(context.Background(), snapshot, ".", "sh", "-c", "cat ../outside >> go.mod && echo y > go.sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := snapshot.Changes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Path != "go.mod" || changes[1].Path != "go.sum" {
+		t.Fatalf("expected changes of go.mod and go.sum, got %+v", changes)
+	}
+	if err := snapshot.Restore(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "go.mod")); string(data) != "module a\n" {
+		t.Fatalf("go.mod wasn't restored: %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "go.sum")); !os.IsNotExist(err) {
+		t.Fatalf("go.sum wasn't removed: %v", err)
+	}
+}
+
+func TestRunCommandInCopyResolvesReplaces(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	parent := t.TempDir()
+	if err := os.Mkdir(filepath.Join(parent, "lib"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(parent, "connector")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	goMod := "module a\n\nreplace example.com/lib => ../lib\n"
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// In a dry run, the command runs in a copy of the connector, where the
+	// replace directive points to the original location.
+	overlay := NewOverlay(NewOSFS(dir))
+	err := runCommand(context.Background(), overlay, ".", "sh", "-c", `test -d "$(sed -n 's/.*=> //p' go.mod)" && echo "require example.com/lib v0.0.0" >> go.mod`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := overlay.ReadFile("go.mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := goMod + "\nrequire example.com/lib v0.0.0\n"; string(got) != want {
+		t.Fatalf("unexpected go.mod:\n%s", got)
+	}
+}
+
+func TestSyncFromDirKeepsUncopiedFiles(t *testing.T) {
+	fsys := NewMemFS()
+	for _, name := range []string{"go.mod", "removed.go", "uncopied"} {
+		if err := fsys.WriteFile(name, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	copied := map[string]bool{"go.mod": true, "removed.go": true}
+	if err := syncFromDir(fsys, dir, copied); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := fsys.ReadFile("go.mod"); string(data) != "changed" {
+		t.Fatalf("go.mod wasn't synced: %q", data)
+	}
+	if _, err := fsys.ReadFile("removed.go"); err == nil {
+		t.Fatal("removed.go wasn't removed")
+	}
+	if _, err := fsys.ReadFile("uncopied"); err != nil {
+		t.Fatalf("uncopied was removed: %v", err)
+	}
+}
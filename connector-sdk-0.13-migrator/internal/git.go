@@ -0,0 +1,119 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// MigrationBranch is the branch the migration is committed to.
+const MigrationBranch = "migrate/sdk-v0.13"
+
+// Git runs git commands in the repository of a connector.
+type Git struct {
+	dir string
+}
+
+func NewGit(dir string) Git {
+	return Git{dir: dir}
+}
+
+// EnsureClean returns an error if the working tree has uncommitted changes or
+// untracked files, not counting the excluded paths.
+func (g Git) EnsureClean(exclude ...string) error {
+	args := []string{"status", "--porcelain", "--", "."}
+	for _, p := range exclude {
+		args = append(args, ":(exclude)"+p)
+	}
+	out, err := g.run(args...)
+	if err != nil {
+		return err
+	}
+	if out != "" {
+		return fmt.Errorf("working tree of %s is not clean, commit or stash your changes first:\n%s", g.dir, out)
+	}
+	return nil
+}
+
+// CurrentBranch returns the name of the checked out branch, or the commit
+// hash if HEAD is detached.
+func (g Git) CurrentBranch() (string, error) {
+	branch, err := g.run("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	if branch == "HEAD" {
+		return g.run("rev-parse", "HEAD")
+	}
+	return branch, nil
+}
+
+// CreateBranch creates a new branch and checks it out.
+func (g Git) CreateBranch(name string) error {
+	_, err := g.run("checkout", "-b", name)
+	return err
+}
+
+// Abandon checks out branch again and deletes the branch created with
+// CreateBranch. The working tree needs to match branch.
+func (g Git) Abandon(branch, created string) error {
+	if _, err := g.run("checkout", "--force", branch); err != nil {
+		return err
+	}
+	_, err := g.run("branch", "--delete", "--force", created)
+	return err
+}
+
+// Commit stages the changes and commits them with the given message. Deleted
+// files are staged as removals. Nothing is committed if there are no changes.
+func (g Git) Commit(message string, changes []Change) error {
+	var added, removed []string
+	for _, c := range changes {
+		if c.Op == ChangeDeleted {
+			removed = append(removed, c.Path)
+		} else {
+			added = append(added, c.Path)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	if len(added) > 0 {
+		if _, err := g.run(append([]string{"add", "--"}, added...)...); err != nil {
+			return err
+		}
+	}
+	if len(removed) > 0 {
+		if _, err := g.run(append([]string{"rm", "--quiet", "--cached", "--ignore-unmatch", "--"}, removed...)...); err != nil {
+			return err
+		}
+	}
+
+	_, err := g.run("commit", "--quiet", "--message", message)
+	return err
+}
+
+func (g Git) run(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = g.dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w\nOutput: %s", args[0], err, out)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,155 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestRepo creates a git repository with a committed go.mod and
+// source/source.go on the main branch.
+func newTestRepo(t *testing.T) (string, Git) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]os.FileMode{
+		"go.mod":           0644,
+		"source/source.go": 0644,
+	})
+	g := NewGit(dir)
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch", "main"},
+		{"config", "user.name", "test"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "commit.gpgsign", "false"},
+		{"add", "."},
+		{"commit", "--quiet", "--message", "initial"},
+	} {
+		if _, err := g.run(args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir, g
+}
+
+func TestGitEnsureClean(t *testing.T) {
+	dir, g := newTestRepo(t)
+	if err := g.EnsureClean(); err != nil {
+		t.Fatalf("expected a clean working tree: %v", err)
+	}
+
+	writeTestFiles(t, dir, map[string]os.FileMode{StateFile: 0644})
+	if err := g.EnsureClean(); err == nil {
+		t.Error("expected an untracked file to be reported")
+	}
+	if err := g.EnsureClean(StateFile); err != nil {
+		t.Errorf("expected the excluded file to be ignored: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := g.EnsureClean(StateFile)
+	if err == nil || !strings.Contains(err.Error(), "go.mod") {
+		t.Errorf("expected the modified go.mod to be reported, got %v", err)
+	}
+}
+
+func TestGitCreateBranchAndCommit(t *testing.T) {
+	dir, g := newTestRepo(t)
+	if err := g.CreateBranch(MigrationBranch); err != nil {
+		t.Fatal(err)
+	}
+	if branch, err := g.CurrentBranch(); err != nil || branch != MigrationBranch {
+		t.Fatalf("expected %s to be checked out, got %q, %v", MigrationBranch, branch, err)
+	}
+	if err := g.CreateBranch(MigrationBranch); err == nil {
+		t.Error("expected creating an existing branch to fail")
+	}
+
+	// Nothing to commit.
+	if err := g.Commit("empty", nil); err != nil {
+		t.Fatal(err)
+	}
+	if msg, _ := g.run("log", "-1", "--format=%s"); msg != "initial" {
+		t.Errorf("expected no commit without changes, got %q", msg)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFiles(t, dir, map[string]os.FileMode{"dest/dest.go": 0644, "untouched": 0644})
+	if err := os.Remove(filepath.Join(dir, "source/source.go")); err != nil {
+		t.Fatal(err)
+	}
+	err := g.Commit("migrate", []Change{
+		{Path: "go.mod", Op: ChangeModified},
+		{Path: "dest/dest.go", Op: ChangeCreated},
+		{Path: "source/source.go", Op: ChangeDeleted},
+		// Created and removed again by a later migrator.
+		{Path: "tmp", Op: ChangeDeleted},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if msg, _ := g.run("log", "-1", "--format=%s"); msg != "migrate" {
+		t.Errorf("expected the migration to be committed, got %q", msg)
+	}
+	files, err := g.run("ls-files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "dest/dest.go\ngo.mod"; files != want {
+		t.Errorf("expected the tracked files %q, got %q", want, files)
+	}
+	// Only the changes are committed.
+	if status, _ := g.run("status", "--porcelain"); status != "?? untouched" {
+		t.Errorf("expected only the untouched file to be left, got %q", status)
+	}
+}
+
+func TestGitAbandon(t *testing.T) {
+	dir, g := newTestRepo(t)
+	if err := g.CreateBranch(MigrationBranch); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Commit("migrate", []Change{{Path: "go.mod", Op: ChangeModified}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.Abandon("main", MigrationBranch); err != nil {
+		t.Fatal(err)
+	}
+	if branch, err := g.CurrentBranch(); err != nil || branch != "main" {
+		t.Errorf("expected main to be checked out, got %q, %v", branch, err)
+	}
+	if branches, _ := g.run("branch", "--list", MigrationBranch); branches != "" {
+		t.Errorf("expected %s to be deleted, got %q", MigrationBranch, branches)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "go.mod")); string(data) != "go.mod" {
+		t.Errorf("expected go.mod of main, got %q", data)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the expected files of the golden tests")
+
+// commandsFile lists the external commands a migrator ran in a test case.
+// The commands aren't actually run.
+const commandsFile = "commands.txt"
+
+// TestGolden runs every registered migrator on the input/ tree of its test
+// cases in testdata/<migrator>/<case> and compares the result with the
+// expected/ tree. Run the tests with -update to regenerate the expected trees.
+func TestGolden(t *testing.T) {
+	for _, m := range DefaultRegistry().Migrators() {
+		name := MigratorName(m)
+		cases, err := os.ReadDir(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("migrator %s has no test cases: %v", name, err)
+		}
+		for _, c := range cases {
+			if !c.IsDir() {
+				continue
+			}
+			t.Run(name+"/"+c.Name(), func(t *testing.T) {
+				testGolden(t, m, filepath.Join("testdata", name, c.Name()))
+			})
+		}
+	}
+}
+
+func testGolden(t *testing.T, m Migrator, dir string) {
+	report := newStepReport(m)
+	ctx := withStepReport(context.Background(), report)
+	fsys := loadTree(t, filepath.Join(dir, "input"))
+	stubCommands(t)
+
+	status, err := m.Check(fsys)
+	if err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if status != StatusNeedsMigration {
+		t.Fatalf("expected input to need migration, got %v", status)
+	}
+
+	if err := m.Migrate(ctx, fsys); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	var commands []string
+	for _, c := range report.Commands {
+		commands = append(commands, c.Dir+": "+c.Command)
+	}
+
+	// The results of commands can't be checked, since they don't run.
+	if len(commands) == 0 {
+		status, err = m.Check(fsys)
+		if err != nil {
+			t.Fatalf("check after migration failed: %v", err)
+		}
+		if status == StatusNeedsMigration {
+			t.Errorf("expected migration to be applied, got %v", status)
+		}
+	}
+
+	gotCommands := strings.Join(commands, "\n")
+	if *update {
+		writeTree(t, fsys, filepath.Join(dir, "expected"))
+		writeCommands(t, filepath.Join(dir, commandsFile), gotCommands)
+		return
+	}
+
+	want := loadTree(t, filepath.Join(dir, "expected"))
+	if diff := diffTrees(t, want, fsys); diff != "" {
+		t.Errorf("result differs from expected (-want +got):\n%s", diff)
+	}
+	wantCommands, err := os.ReadFile(filepath.Join(dir, commandsFile))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		t.Fatal(err)
+	}
+	if diff := unifiedDiff("want", "got", wantCommands, []byte(commandsText(gotCommands))); diff != "" {
+		t.Errorf("commands differ from expected (-want +got):\n%s", diff)
+	}
+}
+
+// stubCommands replaces the commands run by runCommand with a no-op. The
+// commands are still recorded in the step report.
+func stubCommands(t *testing.T) {
+	t.Helper()
+	noop, err := exec.LookPath("true")
+	if err != nil {
+		t.Skip("true command not available")
+	}
+
+	orig := execCommand
+	t.Cleanup(func() { execCommand = orig })
+	execCommand = func(ctx context.Context, _ string, _ ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, noop)
+	}
+}
+
+func commandsText(commands string) string {
+	if commands == "" {
+		return ""
+	}
+	return commands + "\n"
+}
+
+func writeCommands(t *testing.T, name, commands string) {
+	t.Helper()
+	if commands == "" {
+		if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			t.Fatal(err)
+		}
+		return
+	}
+	if err := os.WriteFile(name, []byte(commandsText(commands)), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// loadTree reads the directory on disk into a MemFS.
+func loadTree(t *testing.T, dir string) *MemFS {
+	t.Helper()
+	fsys := NewMemFS()
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return fsys.WriteFile(filepath.ToSlash(rel), data, info.Mode().Perm())
+	})
+	if err != nil {
+		t.Fatalf("failed loading %s: %v", dir, err)
+	}
+	return fsys
+}
+
+// writeTree replaces the directory on disk with the contents of fsys.
+func writeTree(t *testing.T, fsys *MemFS, dir string) {
+	t.Helper()
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range treeFiles(fsys) {
+		f := fsys.files[p]
+		name := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, f.data, f.perm); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// diffTrees returns a unified diff of all files that differ between want and
+// got, or an empty string if the trees are equal.
+func diffTrees(t *testing.T, want, got *MemFS) string {
+	t.Helper()
+	var buf strings.Builder
+	paths := append(treeFiles(want), treeFiles(got)...)
+	slices.Sort(paths)
+	for _, p := range slices.Compact(paths) {
+		w, inWant := want.files[p]
+		g, inGot := got.files[p]
+		oldName, newName := path.Join("want", p), path.Join("got", p)
+		switch {
+		case !inWant:
+			buf.WriteString(unifiedDiff("/dev/null", newName, nil, g.data))
+		case !inGot:
+			buf.WriteString(unifiedDiff(oldName, "/dev/null", w.data, nil))
+		case !bytes.Equal(w.data, g.data):
+			buf.WriteString(unifiedDiff(oldName, newName, w.data, g.data))
+		case w.perm != g.perm:
+			fmt.Fprintf(&buf, "%s: mode %v, want %v\n", p, g.perm, w.perm)
+		}
+	}
+	return buf.String()
+}
+
+func treeFiles(fsys *MemFS) []string {
+	paths := make([]string, 0, len(fsys.files))
+	for p := range fsys.files {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+	return paths
+}
@@ -0,0 +1,231 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"go/version"
+	"io/fs"
+	"path/filepath"
+	"slices"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	commonsModule  = "github.com/conduitio/conduit-commons"
+	protocolModule = "github.com/conduitio/conduit-connector-protocol"
+)
+
+// Compatibility describes what a connector needs when upgrading to an SDK
+// version.
+type Compatibility struct {
+	// SDK is the SDK version the entry applies to. It also applies to later
+	// versions, up to the next entry.
+	SDK string
+	// Go is the minimum version in the go directive.
+	Go string
+	// Toolchain is the minimum version in the toolchain directive, if any.
+	Toolchain string
+	// Modules are the minimum versions of modules that need to be upgraded
+	// together with the SDK.
+	Modules map[string]string
+}
+
+// compatibilityMatrix lists the requirements of SDK versions, ordered by
+// version.
+var compatibilityMatrix = []Compatibility{
+	{
+		SDK: "v0.13.0",
+		Go:  "1.23.2",
+		Modules: map[string]string{
+			commonsModule:  "v0.5.0",
+			protocolModule: "v0.9.0",
+		},
+	},
+}
+
+// compatibility returns the requirements of the given SDK version.
+func compatibility(sdkVersion string) (Compatibility, error) {
+	for _, c := range slices.Backward(compatibilityMatrix) {
+		if semver.Compare(c.SDK, sdkVersion) <= 0 {
+			return c, nil
+		}
+	}
+	return Compatibility{}, fmt.Errorf("no compatibility information for SDK %s", sdkVersion)
+}
+
+// GoMod is a parsed go.mod file that can be edited and written back.
+type GoMod struct {
+	name string
+	file *modfile.File
+}
+
+// ReadGoMod parses the named go.mod file in fsys.
+func ReadGoMod(fsys FS, name string) (*GoMod, error) {
+	data, err := fsys.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := modfile.Parse(name, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing %s: %w", name, err)
+	}
+	return &GoMod{name: name, file: f}, nil
+}
+
+// Require returns the required version of the module, or an empty string if
+// it's not required.
+func (m *GoMod) Require(path string) string {
+	for _, r := range m.file.Require {
+		if r.Mod.Path == path {
+			return r.Mod.Version
+		}
+	}
+	return ""
+}
+
+// SetRequire requires the module in the given version. Indirect requirements
+// stay indirect.
+func (m *GoMod) SetRequire(path, version string) error {
+	return m.file.AddRequire(path, version)
+}
+
+// Upgrade raises the required version of a module to version. Modules that
+// aren't required or are already on a later version aren't changed.
+func (m *GoMod) Upgrade(path, version string) error {
+	current := m.Require(path)
+	if current == "" || semver.Compare(current, version) >= 0 {
+		return nil
+	}
+	return m.SetRequire(path, version)
+}
+
+// UpgradeGo raises the go directive to goVersion (e.g. "1.23.2") and the
+// toolchain directive to toolchain (e.g. "go1.23.4"), if set. A toolchain
+// directive that isn't newer than the go directive anymore is dropped, since
+// it has no effect.
+func (m *GoMod) UpgradeGo(goVersion, toolchain string) error {
+	if m.file.Go == nil || version.Compare("go"+m.file.Go.Version, "go"+goVersion) < 0 {
+		if err := m.file.AddGoStmt(goVersion); err != nil {
+			return err
+		}
+	}
+
+	current := ""
+	if m.file.Toolchain != nil {
+		current = m.file.Toolchain.Name
+	}
+	if toolchain != "" && version.Compare(current, toolchain) < 0 {
+		if err := m.file.AddToolchainStmt(toolchain); err != nil {
+			return err
+		}
+		current = toolchain
+	}
+	if current != "" && version.Compare(current, "go"+m.file.Go.Version) <= 0 {
+		m.file.DropToolchainStmt()
+	}
+	return nil
+}
+
+// Tools returns the packages of the tool directives.
+func (m *GoMod) Tools() []string {
+	tools := make([]string, len(m.file.Tool))
+	for i, t := range m.file.Tool {
+		tools[i] = t.Path
+	}
+	return tools
+}
+
+// AddTool adds a tool directive for the package, unless it already exists.
+func (m *GoMod) AddTool(path string) error {
+	return m.file.AddTool(path)
+}
+
+// DropReplaces removes all replace directives of the module and returns
+// them.
+func (m *GoMod) DropReplaces(path string) ([]*modfile.Replace, error) {
+	var dropped []*modfile.Replace
+	for _, r := range slices.Clone(m.file.Replace) {
+		if r.Old.Path != path {
+			continue
+		}
+		if err := m.file.DropReplace(r.Old.Path, r.Old.Version); err != nil {
+			return nil, err
+		}
+		dropped = append(dropped, r)
+	}
+	return dropped, nil
+}
+
+// ResolveReplaces makes the relative paths of replace directives absolute,
+// resolving them against dir. It returns the original paths by the absolute
+// ones, see RestoreReplaces.
+func (m *GoMod) ResolveReplaces(dir string) (map[string]string, error) {
+	paths := make(map[string]string)
+	for _, r := range slices.Clone(m.file.Replace) {
+		if r.New.Version != "" || filepath.IsAbs(r.New.Path) {
+			continue
+		}
+		original, abs := r.New.Path, filepath.Join(dir, r.New.Path)
+		if err := m.file.AddReplace(r.Old.Path, r.Old.Version, abs, ""); err != nil {
+			return nil, err
+		}
+		paths[abs] = original
+	}
+	return paths, nil
+}
+
+// RestoreReplaces sets the paths of replace directives made absolute by
+// ResolveReplaces back to the original ones.
+func (m *GoMod) RestoreReplaces(paths map[string]string) error {
+	for _, r := range slices.Clone(m.file.Replace) {
+		original, ok := paths[r.New.Path]
+		if !ok || r.New.Version != "" {
+			continue
+		}
+		if err := m.file.AddReplace(r.Old.Path, r.Old.Version, original, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write formats the file and writes it to fsys.
+func (m *GoMod) Write(fsys FS) error {
+	m.file.Cleanup()
+	data, err := m.file.Format()
+	if err != nil {
+		return fmt.Errorf("failed formatting %s: %w", m.name, err)
+	}
+	perm := fs.FileMode(0644)
+	if info, err := fsys.Stat(m.name); err == nil {
+		perm = info.Mode().Perm()
+	}
+	return fsys.WriteFile(m.name, data, perm)
+}
+
+// replaceString describes a replace directive like in go.mod.
+func replaceString(r *modfile.Replace) string {
+	old, new := r.Old.Path, r.New.Path
+	if r.Old.Version != "" {
+		old += " " + r.Old.Version
+	}
+	if r.New.Version != "" {
+		new += " " + r.New.Version
+	}
+	return old + " => " + new
+}
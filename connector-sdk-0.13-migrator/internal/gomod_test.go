@@ -0,0 +1,130 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"testing"
+)
+
+func TestGoModUpgradeGo(t *testing.T) {
+	testCases := []struct {
+		name      string
+		directive string
+		goVersion string
+		toolchain string
+		want      string
+	}{{
+		name:      "raise go, drop older toolchain",
+		directive: "go 1.22\n\ntoolchain go1.22.5\n",
+		goVersion: "1.23.2",
+		want:      "go 1.23.2\n",
+	}, {
+		name:      "keep newer toolchain",
+		directive: "go 1.22\n\ntoolchain go1.24.0\n",
+		goVersion: "1.23.2",
+		want:      "go 1.23.2\n\ntoolchain go1.24.0\n",
+	}, {
+		name:      "keep newer go",
+		directive: "go 1.24.1\n",
+		goVersion: "1.23.2",
+		want:      "go 1.24.1\n",
+	}, {
+		name:      "raise toolchain",
+		directive: "go 1.23.2\n",
+		goVersion: "1.23.2",
+		toolchain: "go1.23.4",
+		want:      "go 1.23.2\n\ntoolchain go1.23.4\n",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFS()
+			if err := fsys.WriteFile("go.mod", []byte("module example.com/m\n\n"+tc.directive), 0644); err != nil {
+				t.Fatal(err)
+			}
+			mod, err := ReadGoMod(fsys, "go.mod")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := mod.UpgradeGo(tc.goVersion, tc.toolchain); err != nil {
+				t.Fatal(err)
+			}
+			if err := mod.Write(fsys); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := fsys.ReadFile("go.mod")
+			if want := "module example.com/m\n\n" + tc.want; string(got) != want {
+				t.Errorf("unexpected go.mod:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestGoModResolveReplaces(t *testing.T) {
+	testCases := []struct {
+		name    string
+		replace string
+		want    string
+	}{{
+		name:    "relative path",
+		replace: "replace example.com/lib => ../lib\n",
+		want:    "replace example.com/lib => /src/lib\n",
+	}, {
+		name:    "relative path of a version",
+		replace: "replace example.com/lib v1.0.0 => ./lib\n",
+		want:    "replace example.com/lib v1.0.0 => /src/m/lib\n",
+	}, {
+		name:    "absolute path",
+		replace: "replace example.com/lib => /lib\n",
+		want:    "replace example.com/lib => /lib\n",
+	}, {
+		name:    "module",
+		replace: "replace example.com/lib => example.com/fork v1.0.0\n",
+		want:    "replace example.com/lib => example.com/fork v1.0.0\n",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFS()
+			if err := fsys.WriteFile("go.mod", []byte("module example.com/m\n\n"+tc.replace), 0644); err != nil {
+				t.Fatal(err)
+			}
+			mod, err := ReadGoMod(fsys, "go.mod")
+			if err != nil {
+				t.Fatal(err)
+			}
+			paths, err := mod.ResolveReplaces("/src/m")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := mod.Write(fsys); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := fsys.ReadFile("go.mod")
+			if want := "module example.com/m\n\n" + tc.want; string(got) != want {
+				t.Errorf("unexpected go.mod:\n%s", got)
+			}
+
+			if err := mod.RestoreReplaces(paths); err != nil {
+				t.Fatal(err)
+			}
+			if err := mod.Write(fsys); err != nil {
+				t.Fatal(err)
+			}
+			got, _ = fsys.ReadFile("go.mod")
+			if want := "module example.com/m\n\n" + tc.replace; string(got) != want {
+				t.Errorf("unexpected restored go.mod:\n%s", got)
+			}
+		})
+	}
+}
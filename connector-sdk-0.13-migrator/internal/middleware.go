@@ -0,0 +1,275 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// UpdateMiddleware migrates the middleware of sources and destinations. In
+// SDK v0.13, the middleware is configured by embedding it in the config
+// struct, instead of passing it to SourceWithMiddleware or
+// DestinationWithMiddleware.
+type UpdateMiddleware struct{}
+
+func (u UpdateMiddleware) Description() string {
+	return "Embed the default middleware in the config structs"
+}
+
+func (u UpdateMiddleware) Check(fsys FS) (Status, error) {
+	pkgs, err := parsePackages(fsys)
+	if err != nil {
+		return 0, err
+	}
+	calls := u.findCalls(pkgs)
+	if len(calls) == 0 {
+		return StatusNotApplicable, nil
+	}
+	for _, call := range calls {
+		if len(call.expr.Args) > 1 && !u.marked(call) {
+			return StatusNeedsMigration, nil
+		}
+	}
+	return StatusAlreadyApplied, nil
+}
+
+func (u UpdateMiddleware) Migrate(ctx context.Context, fsys FS) error {
+	pkgs, err := parsePackages(fsys)
+	if err != nil {
+		return err
+	}
+	report := StepReportFromContext(ctx)
+
+	edits := newGoEdits()
+	embedded := make(map[*ast.TypeSpec]bool)
+	for _, call := range u.findCalls(pkgs) {
+		if len(call.expr.Args) == 1 || u.marked(call) {
+			continue
+		}
+		pos := call.pkg.fset.Position(call.expr.Pos())
+		middleware, ok := u.defaultMiddleware(call)
+		if !ok {
+			report.Warnf("%s: %s is called with custom middleware, embed the middleware in the config struct manually", pos, call.name())
+			continue
+		}
+		// Without the middleware embedded in the config struct, removing the
+		// arguments would drop the middleware.
+		cfgType, ok := u.configType(call)
+		if !ok {
+			report.Warnf("%s: couldn't find the config struct of the %s, embed %s.%s in it and remove the middleware arguments of %s manually", pos, strings.ToLower(call.plugin.iface), call.alias, u.middlewareType(call.plugin), call.name())
+			continue
+		}
+		if !embedded[cfgType.spec] {
+			embedded[cfgType.spec] = true
+			u.embed(ctx, call.pkg, cfgType, call.plugin, edits)
+		}
+
+		// Dropping the options would change the behavior of the connector,
+		// so the call is left to be migrated manually.
+		if len(middleware.Args) > 0 {
+			u.todo(call, edits)
+			report.Warnf("%s: the options passed to %s.%s need to be set as defaults of the embedded middleware manually", pos, call.alias, u.middlewareType(call.plugin))
+			continue
+		}
+
+		// sdk.SourceWithMiddleware(&Source{}, sdk.DefaultSourceMiddleware()...)
+		// becomes sdk.SourceWithMiddleware(&Source{})
+		edits.add(call.file, textEdit{
+			start: call.pkg.offset(call.expr.Lparen) + 1,
+			end:   call.pkg.offset(call.expr.Rparen),
+			text:  call.file.text(call.expr.Args[0]),
+		})
+		report.Notef("%s: removed the middleware arguments of %s", pos, call.name())
+	}
+
+	names, err := edits.write(fsys)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		content, err := fsys.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		report.AddTODOs(name, content, middlewareTODO)
+	}
+	return nil
+}
+
+// todo marks the call with a TODO, at the start of its line.
+func (u UpdateMiddleware) todo(call middlewareCall, edits *goEdits) {
+	file := call.pkg.fset.File(call.expr.Pos())
+	pos := call.pkg.offset(file.LineStart(file.Line(call.expr.Pos())))
+	todo := "// " + middlewareTODO + "\n" +
+		"// Set them as defaults of the middleware embedded in the config struct\n" +
+		"// and remove the arguments.\n"
+	edits.add(call.file, textEdit{start: pos, end: pos, text: todo})
+}
+
+// marked reports whether the call is marked with a TODO by todo.
+func (u UpdateMiddleware) marked(call middlewareCall) bool {
+	line := call.pkg.fset.Position(call.expr.Pos()).Line
+	for _, group := range call.file.ast.Comments {
+		if call.pkg.fset.Position(group.End()).Line == line-1 && strings.Contains(group.Text(), middlewareTODO) {
+			return true
+		}
+	}
+	return false
+}
+
+// middlewareCall is a call of SourceWithMiddleware or
+// DestinationWithMiddleware.
+type middlewareCall struct {
+	pkg    *goPackage
+	file   *goFile
+	expr   *ast.CallExpr
+	plugin plugin
+	alias  string
+}
+
+func (c middlewareCall) name() string {
+	return c.alias + "." + c.plugin.iface + "WithMiddleware"
+}
+
+// findCalls returns the calls of SourceWithMiddleware and
+// DestinationWithMiddleware in all packages.
+func (u UpdateMiddleware) findCalls(pkgs []*goPackage) []middlewareCall {
+	var calls []middlewareCall
+	for _, pkg := range pkgs {
+		for _, f := range pkg.files {
+			alias, ok := importName(f.ast, sdkModule)
+			if !ok {
+				continue
+			}
+			ast.Inspect(f.ast, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				for _, p := range []plugin{sourcePlugin, destinationPlugin} {
+					if isSelector(call.Fun, alias, p.iface+"WithMiddleware") && len(call.Args) > 0 {
+						calls = append(calls, middlewareCall{pkg: pkg, file: f, expr: call, plugin: p, alias: alias})
+					}
+				}
+				return true
+			})
+		}
+	}
+	return calls
+}
+
+// middlewareType returns the name of the default middleware of the plugin,
+// e.g. DefaultSourceMiddleware.
+func (u UpdateMiddleware) middlewareType(p plugin) string {
+	return "Default" + p.iface + "Middleware"
+}
+
+// defaultMiddleware returns the call of the default middleware if it's the
+// only middleware passed, i.e. sdk.DefaultSourceMiddleware(opts...)...
+func (u UpdateMiddleware) defaultMiddleware(call middlewareCall) (*ast.CallExpr, bool) {
+	if len(call.expr.Args) != 2 || !call.expr.Ellipsis.IsValid() {
+		return nil, false
+	}
+	middleware, ok := call.expr.Args[1].(*ast.CallExpr)
+	if !ok || !isSelector(middleware.Fun, call.alias, u.middlewareType(call.plugin)) {
+		return nil, false
+	}
+	return middleware, true
+}
+
+// configType returns the config struct of the wrapped source or destination,
+// i.e. the type of the field returned by its Config method.
+func (u UpdateMiddleware) configType(call middlewareCall) (configType, bool) {
+	arg := call.expr.Args[0]
+	if unary, ok := arg.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		arg = unary.X
+	}
+	lit, ok := arg.(*ast.CompositeLit)
+	if !ok {
+		return configType{}, false
+	}
+	ident, ok := lit.Type.(*ast.Ident)
+	if !ok {
+		return configType{}, false
+	}
+
+	config := call.pkg.methods(ident.Name)["Config"]
+	impl, ok := call.pkg.typeDecl(ident.Name)
+	if config == nil || !ok || config.decl.Body == nil {
+		return configType{}, false
+	}
+	// return &s.config, or return s.config if the field is a pointer,
+	// possibly after initializing it.
+	var sel *ast.SelectorExpr
+	ast.Inspect(config.decl.Body, func(n ast.Node) bool {
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 || sel != nil {
+			return sel == nil
+		}
+		result := ret.Results[0]
+		if unary, ok := result.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+			result = unary.X
+		}
+		sel, _ = result.(*ast.SelectorExpr)
+		return false
+	})
+	if sel == nil {
+		return configType{}, false
+	}
+	typeName, _, ok := structFieldType(impl.spec, sel.Sel.Name)
+	if !ok {
+		return configType{}, false
+	}
+	return call.pkg.typeDecl(typeName)
+}
+
+// embed adds the default middleware as the first field of the config
+// struct. A Validate method of the config struct hides the one of the
+// middleware, so it's called from there.
+func (u UpdateMiddleware) embed(ctx context.Context, pkg *goPackage, cfgType configType, p plugin, edits *goEdits) {
+	alias, ok := importName(cfgType.file.ast, sdkModule)
+	if !ok {
+		alias = "sdk"
+		edits.addImport(cfgType.file, sdkModule, "sdk")
+	}
+	middleware := u.middlewareType(p)
+
+	st := cfgType.spec.Type.(*ast.StructType)
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 && isSelector(f.Type, alias, middleware) {
+			return
+		}
+	}
+	lbrace := pkg.offset(st.Fields.Opening) + 1
+	edits.add(cfgType.file, textEdit{start: lbrace, end: lbrace, text: "\n" + alias + "." + middleware + "\n"})
+
+	validate := pkg.methods(cfgType.spec.Name.Name)["Validate"]
+	if validate == nil || validate.decl.Body == nil {
+		return
+	}
+	recv := fieldName(validate.decl.Recv.List[0])
+	params := validate.decl.Type.Params.List
+	if recv == "" || recv == "_" || len(params) == 0 || fieldName(params[0]) == "" || fieldName(params[0]) == "_" {
+		StepReportFromContext(ctx).Warnf("%s.Validate hides %s.%s.Validate, call it manually", cfgType.spec.Name.Name, alias, middleware)
+		return
+	}
+	call := fmt.Sprintf("\nif err := %s.%s.Validate(%s); err != nil {\nreturn err\n}\n", recv, middleware, fieldName(params[0]))
+	pos := pkg.offset(validate.decl.Body.Lbrace) + 1
+	edits.add(validate.file, textEdit{start: pos, end: pos, text: call})
+}
@@ -0,0 +1,237 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Overlay is an in-memory layer on top of another FS. Reads fall through to
+// the base unless the file was written or removed through the overlay.
+// Writes and removals are kept in memory until Flush is called.
+type Overlay struct {
+	base  FS
+	files map[string]*overlayFile
+}
+
+type overlayFile struct {
+	data    []byte
+	perm    fs.FileMode
+	deleted bool
+}
+
+// ChangeOp describes what happened to a file.
+type ChangeOp string
+
+const (
+	ChangeCreated  ChangeOp = "created"
+	ChangeModified ChangeOp = "modified"
+	ChangeDeleted  ChangeOp = "deleted"
+)
+
+// Change is a single file change recorded in the overlay.
+type Change struct {
+	Path string
+	Op   ChangeOp
+	Old  []byte
+	New  []byte
+}
+
+func NewOverlay(base FS) *Overlay {
+	return &Overlay{
+		base:  base,
+		files: make(map[string]*overlayFile),
+	}
+}
+
+func (o *Overlay) ReadFile(name string) ([]byte, error) {
+	name = path.Clean(name)
+	if f, ok := o.files[name]; ok {
+		if f.deleted {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+		}
+		return bytes.Clone(f.data), nil
+	}
+	return o.base.ReadFile(name)
+}
+
+func (o *Overlay) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	name = path.Clean(name)
+	if info, err := o.Stat(name); err == nil && info.IsDir() {
+		return &fs.PathError{Op: "write", Path: name, Err: errIsDir}
+	}
+	o.files[name] = &overlayFile{data: bytes.Clone(data), perm: perm}
+	return nil
+}
+
+func (o *Overlay) Remove(name string) error {
+	name = path.Clean(name)
+	info, err := o.Stat(name)
+	if err != nil {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	if info.IsDir() {
+		return &fs.PathError{Op: "remove", Path: name, Err: errIsDir}
+	}
+	o.files[name] = &overlayFile{deleted: true}
+	return nil
+}
+
+func (o *Overlay) Stat(name string) (fs.FileInfo, error) {
+	name = path.Clean(name)
+	if f, ok := o.files[name]; ok {
+		if f.deleted {
+			return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+		}
+		return fileInfo{name: path.Base(name), size: int64(len(f.data)), mode: f.perm}, nil
+	}
+
+	info, err := o.base.Stat(name)
+	if err == nil {
+		return info, nil
+	}
+	// The directory might only exist in the overlay.
+	for p, f := range o.files {
+		if !f.deleted && p != name && inDir(p, name) {
+			return dirInfo(name), nil
+		}
+	}
+	return nil, err
+}
+
+// WalkDir walks the file tree rooted at root in lexical order, same as
+// fs.WalkDir, including files that only exist in the overlay.
+func (o *Overlay) WalkDir(root string, fn fs.WalkDirFunc) error {
+	root = path.Clean(root)
+	entries := make(map[string]fs.DirEntry)
+
+	err := o.base.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		entries[p] = d
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	for p, f := range o.files {
+		if !inDir(p, root) {
+			continue
+		}
+		if f.deleted {
+			delete(entries, p)
+			continue
+		}
+		addWalkEntry(entries, root, p, fileInfo{name: path.Base(p), size: int64(len(f.data)), mode: f.perm})
+	}
+
+	if len(entries) == 0 {
+		return fn(root, nil, &fs.PathError{Op: "walk", Path: root, Err: fs.ErrNotExist})
+	}
+	return walkEntries(entries, fn)
+}
+
+// Changes returns all changes recorded in the overlay, sorted by path. Files
+// written with the same contents as in the base are not reported.
+func (o *Overlay) Changes() ([]Change, error) {
+	var changes []Change
+	for p, f := range o.files {
+		old, err := o.base.ReadFile(p)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if c, ok := newChange(p, old, err == nil, f.data, !f.deleted); ok {
+			changes = append(changes, c)
+		}
+	}
+	sortChanges(changes)
+	return changes, nil
+}
+
+// newChange describes the difference between the old and new state of a
+// file. False is returned if nothing changed.
+func newChange(name string, old []byte, existed bool, new []byte, exists bool) (Change, bool) {
+	switch {
+	case existed && !exists:
+		return Change{Path: name, Op: ChangeDeleted, Old: old}, true
+	case !exists:
+		// Created and removed again, nothing changed.
+		return Change{}, false
+	case !existed:
+		return Change{Path: name, Op: ChangeCreated, New: new}, true
+	case !bytes.Equal(old, new):
+		return Change{Path: name, Op: ChangeModified, Old: old, New: new}, true
+	}
+	return Change{}, false
+}
+
+func sortChanges(changes []Change) {
+	slices.SortFunc(changes, func(a, b Change) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+}
+
+// WriteDiff writes a unified diff of all changes in the overlay to w.
+func (o *Overlay) WriteDiff(w io.Writer) error {
+	changes, err := o.Changes()
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		oldName, newName := "a/"+c.Path, "b/"+c.Path
+		switch c.Op {
+		case ChangeCreated:
+			oldName = "/dev/null"
+		case ChangeDeleted:
+			newName = "/dev/null"
+		}
+		diff := unifiedDiff(oldName, newName, c.Old, c.New)
+		if diff == "" {
+			// Empty files that were created or deleted have no hunks.
+			diff = fmt.Sprintf("--- %s\n+++ %s\n", oldName, newName)
+		}
+		if _, err := io.WriteString(w, diff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes all changes to the base and clears the overlay.
+func (o *Overlay) Flush() error {
+	for p, f := range o.files {
+		if f.deleted {
+			err := o.base.Remove(p)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("failed removing %s: %w", p, err)
+			}
+			continue
+		}
+		if err := o.base.WriteFile(p, f.data, f.perm); err != nil {
+			return fmt.Errorf("failed writing %s: %w", p, err)
+		}
+	}
+	clear(o.files)
+	return nil
+}
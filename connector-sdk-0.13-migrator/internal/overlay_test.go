@@ -0,0 +1,140 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"slices"
+	"testing"
+)
+
+func newTestOverlay(t *testing.T) (*MemFS, *Overlay) {
+	t.Helper()
+	base := NewMemFS()
+	for name, content := range map[string]string{
+		"go.mod":           "module a\n",
+		"source/source.go": "package source\n",
+		"empty":            "",
+	} {
+		if err := base.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return base, NewOverlay(base)
+}
+
+func TestOverlayReadWriteRemove(t *testing.T) {
+	base, overlay := newTestOverlay(t)
+
+	if err := overlay.WriteFile("go.mod", []byte("module b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := overlay.WriteFile("dest/dest.go", []byte("package dest\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := overlay.Remove("source/source.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, _ := overlay.ReadFile("go.mod"); string(data) != "module b\n" {
+		t.Errorf("expected the written go.mod, got %q", data)
+	}
+	if _, err := overlay.ReadFile("source/source.go"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the removed file not to exist, got %v", err)
+	}
+	if info, err := overlay.Stat("dest"); err != nil || !info.IsDir() {
+		t.Errorf("expected dest to be a directory, got %v, %v", info, err)
+	}
+	if info, err := overlay.Stat("dest/dest.go"); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected dest/dest.go with mode 0600, got %v, %v", info, err)
+	}
+	if err := overlay.WriteFile("dest", nil, 0644); err == nil {
+		t.Error("expected writing a directory to fail")
+	}
+	if err := overlay.Remove("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected removing a missing file to fail, got %v", err)
+	}
+
+	// The base is unchanged until the overlay is flushed.
+	if data, _ := base.ReadFile("go.mod"); string(data) != "module a\n" {
+		t.Errorf("expected the base to be unchanged, got %q", data)
+	}
+	if err := overlay.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := base.ReadFile("go.mod"); string(data) != "module b\n" {
+		t.Errorf("expected the flushed go.mod, got %q", data)
+	}
+	if _, err := base.ReadFile("source/source.go"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the removed file to be flushed, got %v", err)
+	}
+}
+
+func TestOverlayWalkDir(t *testing.T) {
+	_, overlay := newTestOverlay(t)
+	if err := overlay.WriteFile("dest/dest.go", []byte("package dest\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := overlay.Remove("source/source.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	var files []string
+	err := overlay.WalkDir(".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"dest/dest.go", "empty", "go.mod"}; !slices.Equal(files, want) {
+		t.Errorf("expected files %v, got %v", want, files)
+	}
+}
+
+func TestOverlayWriteDiff(t *testing.T) {
+	_, overlay := newTestOverlay(t)
+	if err := overlay.WriteFile("go.mod", []byte("module b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Written with the same contents, not a change.
+	if err := overlay.WriteFile("source/source.go", []byte("package source\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := overlay.WriteFile("created", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := overlay.Remove("empty"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := overlay.WriteDiff(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "--- /dev/null\n+++ b/created\n" +
+		"--- a/empty\n+++ /dev/null\n" +
+		"--- a/go.mod\n+++ b/go.mod\n@@ -1 +1 @@\n-module a\n+module b\n"
+	if buf.String() != want {
+		t.Errorf("unexpected diff:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,624 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// plugin describes a kind of connector plugin, i.e. a source or a
+// destination, implementing an interface of the SDK.
+type plugin struct {
+	// iface is the name of the SDK interface, e.g. "Source".
+	iface string
+	// methods identify implementations if the packages can't be
+	// type-checked.
+	methods []string
+	// receiver is the receiver name of generated methods.
+	receiver string
+}
+
+var (
+	sourcePlugin = plugin{
+		iface:    "Source",
+		methods:  []string{"Open", "Read", "Ack", "Teardown"},
+		receiver: "s",
+	}
+	destinationPlugin = plugin{
+		iface:    "Destination",
+		methods:  []string{"Open", "Write", "Teardown"},
+		receiver: "d",
+	}
+)
+
+// migratedMethods are changed by the migration, so they're ignored when
+// checking whether a type implements an SDK interface: unmigrated types don't
+// have the methods of the new SDK version yet, migrated types don't have the
+// methods of the old version anymore.
+var migratedMethods = []string{"Config", "Parameters", "Configure"}
+
+// goPackage holds the parsed files of a package directory.
+type goPackage struct {
+	dir   string
+	fset  *token.FileSet
+	files []*goFile
+	// tests are the test files, including external tests.
+	tests []*goFile
+}
+
+type goFile struct {
+	name    string
+	content []byte
+	ast     *ast.File
+	fset    *token.FileSet
+}
+
+// offset returns the offset of pos in the file.
+func (f *goFile) offset(pos token.Pos) int {
+	return f.fset.Position(pos).Offset
+}
+
+// parseGoFile reads and parses the Go file.
+func parseGoFile(fsys FS, fset *token.FileSet, name string) (*goFile, error) {
+	content, err := fsys.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %w", name, err)
+	}
+	file, err := parser.ParseFile(fset, name, content, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing file %s: %w", name, err)
+	}
+	return &goFile{name: name, content: content, ast: file, fset: fset}, nil
+}
+
+// text returns the source code of the node in the file.
+func (f *goFile) text(n ast.Node) string {
+	return string(f.content[f.offset(n.Pos()):f.offset(n.End())])
+}
+
+// offset returns the offset of pos in its file.
+func (p *goPackage) offset(pos token.Pos) int {
+	return p.fset.Position(pos).Offset
+}
+
+// implementation is a named type implementing a plugin interface.
+type implementation struct {
+	pkg  *goPackage
+	name string
+	file *goFile
+	// decl is the declaration of the type.
+	decl *ast.GenDecl
+	spec *ast.TypeSpec
+	// methods are the methods declared on the type, by name.
+	methods map[string]*method
+}
+
+type method struct {
+	file *goFile
+	decl *ast.FuncDecl
+}
+
+func (i *implementation) String() string {
+	return path.Join(i.pkg.dir, i.name)
+}
+
+// configField returns the name of the field holding the configuration and,
+// if it's a pointer, its type. It's the field whose type is generated by
+// paramgen, according to the go:generate directives of the package, or else
+// the field named config.
+func (i *implementation) configField() (string, *ast.StarExpr, bool) {
+	st, ok := i.spec.Type.(*ast.StructType)
+	if !ok {
+		return "", nil, false
+	}
+	generated := i.pkg.paramgenStructs()
+	var fallback *ast.Field
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 {
+			continue
+		}
+		typ := f.Type
+		star, pointer := typ.(*ast.StarExpr)
+		if pointer {
+			typ = star.X
+		}
+		if ident, ok := typ.(*ast.Ident); ok && generated[ident.Name] {
+			return f.Names[0].Name, star, true
+		}
+		for _, name := range f.Names {
+			if name.Name == "config" {
+				fallback = f
+			}
+		}
+	}
+	if fallback == nil {
+		return "", nil, false
+	}
+	star, _ := fallback.Type.(*ast.StarExpr)
+	return "config", star, true
+}
+
+// receiver returns the receiver name used by the methods of the
+// implementation. The most common receiver is returned, or name if the
+// methods have no named receivers.
+func (i *implementation) receiver(name string) string {
+	counts := make(map[string]int)
+	for _, m := range i.methods {
+		recv := m.decl.Recv.List[0]
+		if n := fieldName(recv); n != "" && n != "_" {
+			counts[n]++
+		}
+	}
+	for _, n := range slices.Sorted(maps.Keys(counts)) {
+		if counts[n] > counts[name] {
+			name = n
+		}
+	}
+	return name
+}
+
+// paramgenStructs returns the names of the structs paramgen generates the
+// parameters of, according to the go:generate directives in the package.
+func (p *goPackage) paramgenStructs() map[string]bool {
+	structs := make(map[string]bool)
+	for _, f := range p.files {
+		for _, group := range f.ast.Comments {
+			for _, c := range group.List {
+				if !strings.HasPrefix(c.Text, paramgenDirective) {
+					continue
+				}
+				// The struct name is the only argument that isn't a flag.
+				args := strings.Fields(strings.TrimPrefix(c.Text, paramgenDirective))
+				for i := 0; i < len(args); i++ {
+					switch {
+					case args[i] == "-output" || args[i] == "-path":
+						i++
+					case !strings.HasPrefix(args[i], "-"):
+						structs[args[i]] = true
+					}
+				}
+			}
+		}
+	}
+	return structs
+}
+
+// needsMigration reports whether the implementation still has to be
+// migrated. A migrated implementation has a Config method and no Parameters
+// method.
+func (i *implementation) needsMigration() bool {
+	return i.methods["Parameters"] != nil || i.methods["Config"] == nil
+}
+
+// check reports whether any implementation of the plugin needs to be
+// migrated.
+func (p plugin) check(fsys FS) (Status, error) {
+	impls, err := p.implementations(context.Background(), fsys)
+	if err != nil {
+		return 0, err
+	}
+	if len(impls) == 0 {
+		return StatusNotApplicable, nil
+	}
+	for _, impl := range impls {
+		if impl.needsMigration() {
+			return StatusNeedsMigration, nil
+		}
+	}
+	return StatusAlreadyApplied, nil
+}
+
+// migrate replaces Parameters() with Config() in all implementations of the
+// plugin and moves the logic of their Configure methods to the Validate
+// method of the configuration.
+func (p plugin) migrate(ctx context.Context, fsys FS) error {
+	impls, err := p.implementations(ctx, fsys)
+	if err != nil {
+		return err
+	}
+
+	report := StepReportFromContext(ctx)
+	edits := newGoEdits()
+	for _, impl := range impls {
+		if !impl.needsMigration() {
+			continue
+		}
+		p.edits(ctx, impl, edits)
+		report.Notef("migrated %s %s", strings.ToLower(p.iface), impl)
+		if note := p.configureEdits(impl, edits); note != "" {
+			report.Notef("%s", note)
+		}
+	}
+
+	names, err := edits.write(fsys)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		content, err := fsys.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		report.AddTODOs(name, content, configureTODO)
+	}
+	return nil
+}
+
+// edits adds the edits replacing Parameters() with Config().
+func (p plugin) edits(ctx context.Context, impl *implementation, edits *goEdits) {
+	if m := impl.methods["Parameters"]; m != nil {
+		edits.remove(impl.pkg, m.file, m.decl)
+	}
+	if impl.methods["Config"] != nil {
+		return
+	}
+
+	field, pointer, ok := impl.configField()
+	if !ok {
+		StepReportFromContext(ctx).Warnf("couldn't find the config field of %s, add a Config() method manually", impl)
+		return
+	}
+	alias, ok := importName(impl.file.ast, sdkModule)
+	if !ok {
+		alias = "sdk"
+		edits.addImport(impl.file, sdkModule, alias)
+	}
+
+	// The configuration is parsed into the returned pointer, so it needs
+	// to point to the field of the plugin, not of a copy, and the receiver
+	// needs to be a pointer. A nil pointer field is initialized first.
+	recv := impl.receiver(p.receiver)
+	ref := recv + "." + field
+	body := "return &" + ref
+	if pointer != nil {
+		body = fmt.Sprintf("if %[1]s == nil {\n\t\t%[1]s = &%[2]s{}\n\t}\n\treturn %[1]s", ref, impl.file.text(pointer.X))
+	}
+
+	end := impl.pkg.offset(impl.decl.End())
+	config := fmt.Sprintf("\n\nfunc (%s *%s) Config() %s.%sConfig {\n\t%s\n}",
+		recv, impl.name, alias, p.iface, body)
+	edits.add(impl.file, textEdit{start: end, end: end, text: config})
+}
+
+// implementations returns the types implementing the plugin interface in all
+// packages of the connector. The packages are type-checked to find them. If
+// that's not possible (e.g. the dependencies can't be downloaded), types
+// declaring the methods of the interface are returned.
+func (p plugin) implementations(ctx context.Context, fsys FS) ([]*implementation, error) {
+	pkgs, err := parsePackages(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	implementers, err := sdkImplementers(fsys)
+	if err != nil {
+		StepReportFromContext(ctx).Warnf("couldn't type-check the connector, looking for %s implementations by their methods: %v", strings.ToLower(p.iface), err)
+	}
+
+	var impls []*implementation
+	for _, pkg := range pkgs {
+		for _, f := range pkg.files {
+			for _, decl := range f.ast.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					typeSpec := spec.(*ast.TypeSpec)
+					impl := &implementation{
+						pkg:     pkg,
+						name:    typeSpec.Name.Name,
+						file:    f,
+						decl:    gen,
+						spec:    typeSpec,
+						methods: pkg.methods(typeSpec.Name.Name),
+					}
+					if implementers != nil && implementers[p.iface][typeKey{dir: pkg.dir, name: impl.name}] ||
+						implementers == nil && p.declaresMethods(typeSpec, impl) {
+						impls = append(impls, impl)
+					}
+				}
+			}
+		}
+	}
+	return impls, nil
+}
+
+// declaresMethods reports whether the type is a struct declaring all methods
+// identifying the plugin.
+func (p plugin) declaresMethods(spec *ast.TypeSpec, impl *implementation) bool {
+	if _, ok := spec.Type.(*ast.StructType); !ok {
+		return false
+	}
+	for _, name := range p.methods {
+		if impl.methods[name] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// methods returns the methods declared on the named type in all files of the
+// package.
+func (p *goPackage) methods(typeName string) map[string]*method {
+	methods := make(map[string]*method)
+	for _, f := range p.files {
+		for _, decl := range f.ast.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List) == 0 {
+				continue
+			}
+			if receiverTypeName(fn.Recv.List[0].Type) == typeName {
+				methods[fn.Name.Name] = &method{file: f, decl: fn}
+			}
+		}
+	}
+	return methods
+}
+
+// receiverTypeName returns the name of the receiver type, e.g. Source for
+// *Source.
+func receiverTypeName(expr ast.Expr) string {
+	switch recv := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(recv.X)
+	case *ast.ParenExpr:
+		return receiverTypeName(recv.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(recv.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(recv.X)
+	case *ast.Ident:
+		return recv.Name
+	}
+	return ""
+}
+
+// parsePackages parses all Go files of the connector, grouped by directory.
+// Hidden directories, testdata and vendor are skipped, same as by the go
+// command.
+func parsePackages(fsys FS) ([]*goPackage, error) {
+	fset := token.NewFileSet()
+	byDir := make(map[string]*goPackage)
+	var dirs []string
+	err := fsys.WalkDir(".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != "." && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") {
+			return nil
+		}
+
+		f, err := parseGoFile(fsys, fset, p)
+		if err != nil {
+			return err
+		}
+
+		dir := path.Dir(p)
+		pkg, ok := byDir[dir]
+		if !ok {
+			pkg = &goPackage{dir: dir, fset: fset}
+			byDir[dir] = pkg
+			dirs = append(dirs, dir)
+		}
+		if strings.HasSuffix(name, "_test.go") {
+			pkg.tests = append(pkg.tests, f)
+		} else {
+			pkg.files = append(pkg.files, f)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("error walking dir: %w", err)
+	}
+
+	pkgs := make([]*goPackage, len(dirs))
+	for i, dir := range dirs {
+		pkgs[i] = byDir[dir]
+	}
+	return pkgs, nil
+}
+
+// typeKey identifies a named type by the directory of its package.
+type typeKey struct {
+	dir, name string
+}
+
+// implementersCache keeps the result of sdkImplementers by the hash of the
+// connector files, since loading the packages is expensive and the migrators
+// check them repeatedly.
+var implementersCache = struct {
+	sync.Mutex
+	m map[string]map[string]map[typeKey]bool
+}{m: make(map[string]map[string]map[typeKey]bool)}
+
+// sdkImplementers type-checks the packages of the connector and returns the
+// named types implementing the Source and Destination interfaces of the SDK,
+// by interface name. The methods changed by the migration are ignored.
+func sdkImplementers(fsys FS) (map[string]map[typeKey]bool, error) {
+	hashes, err := hashFiles(fsys)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprint(hashes)
+
+	implementersCache.Lock()
+	defer implementersCache.Unlock()
+	if result, ok := implementersCache.m[key]; ok {
+		return result, nil
+	}
+
+	result, err := loadImplementers(fsys)
+	if err != nil {
+		return nil, err
+	}
+	implementersCache.m[key] = result
+	return result, nil
+}
+
+func loadImplementers(fsys FS) (map[string]map[typeKey]bool, error) {
+	if exists, err := fileExists(fsys, "go.mod"); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, errors.New("go.mod not found")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "connector-sdk-migrator-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	if tmpDir, err = filepath.EvalSymlinks(tmpDir); err != nil {
+		return nil, err
+	}
+	if _, err := copyToDir(fsys, tmpDir); err != nil {
+		return nil, fmt.Errorf("failed to copy connector to %s: %w", tmpDir, err)
+	}
+	// Relative replace directives would point outside of the copy.
+	if _, err := resolveReplaces(fsys, ".", tmpDir); err != nil {
+		return nil, err
+	}
+
+	cfg := &packages.Config{
+		// Dependencies are type-checked from source, export data depends on
+		// the version of the go command.
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedSyntax | packages.NeedTypes,
+		Dir:  tmpDir,
+		// Missing go.sum entries are added in the copy.
+		Env: append(os.Environ(), "GOFLAGS=-mod=mod"),
+		ParseFile: func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+			file, err := parser.ParseFile(fset, filename, src, 0)
+			if file != nil && !strings.HasPrefix(filename, tmpDir+string(filepath.Separator)) {
+				// Only the declarations of dependencies are needed, skipping
+				// function bodies makes type-checking them much faster.
+				for _, decl := range file.Decls {
+					if fn, ok := decl.(*ast.FuncDecl); ok {
+						fn.Body = nil
+					}
+				}
+			}
+			return file, err
+		},
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("failed loading packages: %w", err)
+	}
+
+	result := map[string]map[typeKey]bool{
+		sourcePlugin.iface:      {},
+		destinationPlugin.iface: {},
+	}
+	for _, pkg := range pkgs {
+		// Type errors are expected, since the code isn't migrated yet and
+		// function bodies of dependencies are skipped.
+		for _, pkgErr := range pkg.Errors {
+			if pkgErr.Kind == packages.ListError {
+				return nil, pkgErr
+			}
+		}
+		if pkg.Types == nil || len(pkg.GoFiles) == 0 {
+			continue
+		}
+		sdkPkg := findImport(pkg.Types, sdkModule, make(map[*types.Package]bool))
+		if sdkPkg == nil {
+			continue
+		}
+		dir, err := filepath.Rel(tmpDir, filepath.Dir(pkg.GoFiles[0]))
+		if err != nil {
+			return nil, err
+		}
+
+		scope := pkg.Types.Scope()
+		for _, iface := range slices.Collect(maps.Keys(result)) {
+			obj := sdkPkg.Scope().Lookup(iface)
+			if obj == nil {
+				continue
+			}
+			ifaceType, ok := obj.Type().Underlying().(*types.Interface)
+			if !ok {
+				continue
+			}
+			for _, name := range scope.Names() {
+				typeName, ok := scope.Lookup(name).(*types.TypeName)
+				if !ok || typeName.IsAlias() || types.IsInterface(typeName.Type()) {
+					continue
+				}
+				if implementsIgnoring(typeName.Type(), ifaceType, migratedMethods) {
+					result[iface][typeKey{dir: filepath.ToSlash(dir), name: name}] = true
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// findImport returns the package with the given path imported by pkg,
+// directly or indirectly.
+func findImport(pkg *types.Package, importPath string, seen map[*types.Package]bool) *types.Package {
+	if seen[pkg] {
+		return nil
+	}
+	seen[pkg] = true
+	for _, imp := range pkg.Imports() {
+		if imp.Path() == importPath {
+			return imp
+		}
+		if found := findImport(imp, importPath, seen); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// implementsIgnoring reports whether a pointer to t implements iface, not
+// counting the ignored methods of iface.
+func implementsIgnoring(t types.Type, iface *types.Interface, ignored []string) bool {
+	methods := types.NewMethodSet(types.NewPointer(t))
+	for i := range iface.NumMethods() {
+		m := iface.Method(i)
+		if slices.Contains(ignored, m.Name()) {
+			continue
+		}
+		sel := methods.Lookup(m.Pkg(), m.Name())
+		if sel == nil || !types.Identical(sel.Obj().Type(), m.Type()) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImplementsIgnoring(t *testing.T) {
+	const src = `package p
+
+type Plugin interface {
+	Open() error
+	Parameters() map[string]string
+	Teardown() error
+}
+
+// Pointer implements Plugin with pointer receivers.
+type Pointer struct{}
+
+func (*Pointer) Open() error                   { return nil }
+func (*Pointer) Parameters() map[string]string { return nil }
+func (*Pointer) Teardown() error               { return nil }
+
+// Value implements Plugin with value receivers.
+type Value struct{}
+
+func (Value) Open() error     { return nil }
+func (Value) Teardown() error { return nil }
+
+// Embedded gets its methods from the embedded type.
+type Embedded struct{ Value }
+
+// Missing doesn't declare Teardown.
+type Missing struct{}
+
+func (Missing) Open() error { return nil }
+
+// Mismatch declares Open with another signature.
+type Mismatch struct{}
+
+func (Mismatch) Open(string) error { return nil }
+func (Mismatch) Teardown() error   { return nil }
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := (&types.Config{}).Check("p", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iface := pkg.Scope().Lookup("Plugin").Type().Underlying().(*types.Interface)
+
+	testCases := []struct {
+		typ     string
+		ignored []string
+		want    bool
+	}{
+		{typ: "Pointer", want: true},
+		{typ: "Value", want: false},
+		{typ: "Value", ignored: []string{"Parameters"}, want: true},
+		{typ: "Embedded", ignored: []string{"Parameters"}, want: true},
+		{typ: "Missing", ignored: []string{"Parameters"}, want: false},
+		{typ: "Mismatch", ignored: []string{"Parameters"}, want: false},
+		{typ: "Mismatch", ignored: []string{"Open", "Parameters"}, want: true},
+	}
+	for _, tc := range testCases {
+		typ := pkg.Scope().Lookup(tc.typ).Type()
+		if got := implementsIgnoring(typ, iface, tc.ignored); got != tc.want {
+			t.Errorf("%s ignoring %v: got %v, want %v", tc.typ, tc.ignored, got, tc.want)
+		}
+	}
+}
+
+func TestLoadImplementersRelativeReplace(t *testing.T) {
+	// The connector is type-checked in a copy, where the relative path of
+	// the replace directive has to point to the original location.
+	parent := t.TempDir()
+	files := map[string]string{
+		"lib/go.mod":       "module example.com/lib\n",
+		"lib/lib.go":       "package lib\n\nconst Name = \"lib\"\n",
+		"connector/go.mod": "module example.com/connector\n\nrequire example.com/lib v0.0.0\n\nreplace example.com/lib => ../lib\n",
+		"connector/c.go":   "package connector\n\nimport \"example.com/lib\"\n\nvar name = lib.Name\n",
+	}
+	for name, content := range files {
+		name = filepath.Join(parent, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Also in a dry run, where the changes are kept in memory.
+	osfs := NewOSFS(filepath.Join(parent, "connector"))
+	for _, fsys := range []FS{NewSnapshot(osfs), NewOverlay(osfs)} {
+		if _, err := loadImplementers(fsys); err != nil {
+			t.Fatalf("%T: %v", fsys, err)
+		}
+	}
+}
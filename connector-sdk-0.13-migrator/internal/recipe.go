@@ -0,0 +1,480 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/conduitio/yaml/v3"
+	"golang.org/x/mod/modfile"
+)
+
+//go:embed recipes
+var embeddedRecipes embed.FS
+
+// Recipe is a migrator described in YAML. It consists of steps that rewrite
+// text files, delete files or write files from templates. Example:
+//
+//	name: MakefileMigrator
+//	description: Generate the README with conn-sdk-cli in the Makefile
+//	steps:
+//	  - files: Makefile
+//	    insertAfter:
+//	      anchor: "generate:\n\tgo generate ./..."
+//	      text: "\n\tconn-sdk-cli readmegen -w"
+type Recipe struct {
+	RecipeName        string       `yaml:"name"`
+	RecipeDescription string       `yaml:"description"`
+	Steps             []RecipeStep `yaml:"steps"`
+
+	// source is the file system the recipe was loaded from. Template files
+	// are read relative to dir.
+	source fs.FS
+	dir    string
+}
+
+// RecipeStep is a single step of a recipe. Every step has exactly one
+// action: Replace, Regex, InsertAfter, Delete or WriteTemplate.
+type RecipeStep struct {
+	// Files are the glob patterns of the files the step applies to, relative
+	// to the connector root. "**" matches any number of directories.
+	Files stringList `yaml:"files"`
+	// Contains limits the step to the files that contain the text.
+	Contains string `yaml:"contains"`
+
+	Replace *struct {
+		Old string `yaml:"old"`
+		New string `yaml:"new"`
+	} `yaml:"replace"`
+	Regex *struct {
+		Pattern string `yaml:"pattern"`
+		// Replace can refer to submatches with $1 or ${name}.
+		Replace string `yaml:"replace"`
+
+		regex *regexp.Regexp
+	} `yaml:"regex"`
+	// InsertAfter inserts the text after every occurrence of the anchor,
+	// unless the text is already there.
+	InsertAfter *struct {
+		Anchor string `yaml:"anchor"`
+		Text   string `yaml:"text"`
+	} `yaml:"insertAfter"`
+	Delete        bool `yaml:"delete"`
+	WriteTemplate *struct {
+		// Path is the file that is written.
+		Path string `yaml:"path"`
+		// Template is the text/template of the contents, TemplateFile
+		// is the path to the template relative to the recipe.
+		Template     string `yaml:"template"`
+		TemplateFile string `yaml:"templateFile"`
+		// Mode is the octal file mode, 0644 by default.
+		Mode string `yaml:"mode"`
+
+		template *template.Template
+		mode     fs.FileMode
+	} `yaml:"writeTemplate"`
+}
+
+// stringList is a list of strings, which can be written as a single string
+// in YAML.
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = stringList{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// TemplateData is the data templates of recipes are executed with.
+type TemplateData struct {
+	// Module is the module path of the connector.
+	Module string
+	// Name is the name of the connector, derived from the module path,
+	// e.g. "postgres" for github.com/conduitio/conduit-connector-postgres.
+	Name string
+}
+
+// LoadRecipe parses the recipe in the named file of fsys.
+func LoadRecipe(fsys fs.FS, name string) (*Recipe, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recipe: %w", err)
+	}
+	r := &Recipe{source: fsys, dir: path.Dir(name)}
+	if err := yaml.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("failed to parse recipe %s: %w", name, err)
+	}
+	if err := r.init(); err != nil {
+		return nil, fmt.Errorf("invalid recipe %s: %w", name, err)
+	}
+	return r, nil
+}
+
+// LoadRecipes loads all recipes (*.yaml and *.yml files) in dir on disk,
+// ordered by file name.
+func LoadRecipes(dir string) ([]*Recipe, error) {
+	fsys := os.DirFS(dir)
+	var names []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, matches...)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no recipes found in %s", dir)
+	}
+
+	recipes := make([]*Recipe, len(names))
+	for i, name := range names {
+		r, err := LoadRecipe(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		recipes[i] = r
+	}
+	return recipes, nil
+}
+
+// mustLoadEmbeddedRecipe loads a recipe embedded in the binary.
+func mustLoadEmbeddedRecipe(name string) *Recipe {
+	r, err := LoadRecipe(embeddedRecipes, path.Join("recipes", name))
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// init validates the recipe and compiles its regular expressions and
+// templates.
+func (r *Recipe) init() error {
+	if r.RecipeName == "" {
+		return errors.New("name is required")
+	}
+	if len(r.Steps) == 0 {
+		return errors.New("recipe has no steps")
+	}
+	for i := range r.Steps {
+		if err := r.initStep(&r.Steps[i]); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (r *Recipe) initStep(s *RecipeStep) error {
+	actions := 0
+	for _, set := range []bool{s.Replace != nil, s.Regex != nil, s.InsertAfter != nil, s.Delete, s.WriteTemplate != nil} {
+		if set {
+			actions++
+		}
+	}
+	if actions != 1 {
+		return errors.New("step needs exactly one of replace, regex, insertAfter, delete and writeTemplate")
+	}
+
+	if s.WriteTemplate != nil {
+		if len(s.Files) > 0 || s.Contains != "" {
+			return errors.New("writeTemplate uses path instead of files")
+		}
+		return r.initWriteTemplate(s)
+	}
+
+	if len(s.Files) == 0 {
+		return errors.New("files are required")
+	}
+	for _, pattern := range s.Files {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	switch {
+	case s.Replace != nil && s.Replace.Old == "":
+		return errors.New("replace needs old text")
+	case s.InsertAfter != nil && (s.InsertAfter.Anchor == "" || s.InsertAfter.Text == ""):
+		return errors.New("insertAfter needs anchor and text")
+	case s.Regex != nil:
+		var err error
+		s.Regex.regex, err = regexp.Compile(s.Regex.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *Recipe) initWriteTemplate(s *RecipeStep) error {
+	w := s.WriteTemplate
+	if w.Path == "" {
+		return errors.New("writeTemplate needs path")
+	}
+
+	text := w.Template
+	if w.TemplateFile != "" {
+		if text != "" {
+			return errors.New("writeTemplate needs either template or templateFile")
+		}
+		data, err := fs.ReadFile(r.source, path.Join(r.dir, w.TemplateFile))
+		if err != nil {
+			return fmt.Errorf("failed to read template: %w", err)
+		}
+		text = string(data)
+	}
+	var err error
+	w.template, err = template.New(w.Path).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+
+	w.mode = 0644
+	if w.Mode != "" {
+		mode, err := strconv.ParseUint(w.Mode, 8, 32)
+		if err != nil {
+			return fmt.Errorf("invalid mode %q: %w", w.Mode, err)
+		}
+		w.mode = fs.FileMode(mode).Perm()
+	}
+	return nil
+}
+
+func (r *Recipe) Name() string {
+	return r.RecipeName
+}
+
+func (r *Recipe) Description() string {
+	return r.RecipeDescription
+}
+
+// Check applies the recipe to an in-memory copy of the connector. The recipe
+// needs to run if that changes any file. It doesn't apply if none of the
+// files of its steps exist.
+func (r *Recipe) Check(fsys FS) (Status, error) {
+	overlay := NewOverlay(fsys)
+	applicable, err := r.apply(overlay)
+	if err != nil {
+		return 0, err
+	}
+	changes, err := overlay.Changes()
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case len(changes) > 0:
+		return StatusNeedsMigration, nil
+	case applicable:
+		return StatusAlreadyApplied, nil
+	}
+	return StatusNotApplicable, nil
+}
+
+func (r *Recipe) Migrate(ctx context.Context, fsys FS) error {
+	_, err := r.apply(fsys)
+	return err
+}
+
+// apply runs all steps and reports whether any of them applied to a file.
+func (r *Recipe) apply(fsys FS) (bool, error) {
+	var data *TemplateData
+	applicable := false
+	for i, s := range r.Steps {
+		if s.WriteTemplate != nil {
+			if data == nil {
+				var err error
+				if data, err = templateData(fsys); err != nil {
+					return false, err
+				}
+			}
+			if err := s.writeTemplate(fsys, data); err != nil {
+				return false, fmt.Errorf("step %d: %w", i+1, err)
+			}
+			applicable = true
+			continue
+		}
+
+		files, err := s.files(fsys)
+		if err != nil {
+			return false, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		for _, file := range files {
+			if err := s.applyFile(fsys, file); err != nil {
+				return false, fmt.Errorf("step %d: %s: %w", i+1, file, err)
+			}
+			applicable = true
+		}
+	}
+	return applicable, nil
+}
+
+// files returns the files matching the step.
+func (s RecipeStep) files(fsys FS) ([]string, error) {
+	var files []string
+	err := fsys.WalkDir(".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		for _, pattern := range s.Files {
+			if matchGlob(pattern, p) {
+				files = append(files, p)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil || s.Contains == "" {
+		return files, err
+	}
+
+	matching := files[:0]
+	for _, file := range files {
+		content, err := fsys.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Contains(content, []byte(s.Contains)) {
+			matching = append(matching, file)
+		}
+	}
+	return matching, nil
+}
+
+func (s RecipeStep) applyFile(fsys FS, file string) error {
+	if s.Delete {
+		return fsys.Remove(file)
+	}
+
+	info, err := fsys.Stat(file)
+	if err != nil {
+		return err
+	}
+	data, err := fsys.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	content := string(data)
+
+	var updated string
+	switch {
+	case s.Replace != nil:
+		updated = strings.ReplaceAll(content, s.Replace.Old, s.Replace.New)
+	case s.Regex != nil:
+		updated = s.Regex.regex.ReplaceAllString(content, s.Regex.Replace)
+	case s.InsertAfter != nil:
+		updated = insertAfter(content, s.InsertAfter.Anchor, s.InsertAfter.Text)
+	}
+
+	if updated == content {
+		return nil
+	}
+	return fsys.WriteFile(file, []byte(updated), info.Mode().Perm())
+}
+
+func (s RecipeStep) writeTemplate(fsys FS, data *TemplateData) error {
+	var buf bytes.Buffer
+	if err := s.WriteTemplate.template.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	existing, err := fsys.ReadFile(s.WriteTemplate.Path)
+	if err == nil && bytes.Equal(existing, buf.Bytes()) {
+		return nil
+	}
+	return fsys.WriteFile(s.WriteTemplate.Path, buf.Bytes(), s.WriteTemplate.mode)
+}
+
+// insertAfter inserts text after every occurrence of anchor that isn't
+// already followed by text.
+func insertAfter(content, anchor, text string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(content, anchor)
+		if i == -1 {
+			b.WriteString(content)
+			return b.String()
+		}
+		end := i + len(anchor)
+		b.WriteString(content[:end])
+		content = content[end:]
+		if !strings.HasPrefix(content, text) {
+			b.WriteString(text)
+		}
+	}
+}
+
+// matchGlob reports whether name matches the pattern. "**" matches any
+// number of path elements, other elements are matched with path.Match.
+func matchGlob(pattern, name string) bool {
+	return matchElems(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchElems(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchElems(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// templateData returns the data for the templates of the connector.
+func templateData(fsys FS) (*TemplateData, error) {
+	data := &TemplateData{}
+	content, err := fsys.ReadFile("go.mod")
+	if errors.Is(err, fs.ErrNotExist) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data.Module = modfile.ModulePath(content)
+	data.Name = strings.TrimPrefix(path.Base(data.Module), "conduit-connector-")
+	return data, nil
+}
@@ -0,0 +1,76 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestMatchGlob(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "Makefile", name: "Makefile", want: true},
+		{pattern: "Makefile", name: "sub/Makefile", want: false},
+		{pattern: "*.go", name: "source.go", want: true},
+		{pattern: "*.go", name: "source/source.go", want: false},
+		{pattern: "**/*.go", name: "source.go", want: true},
+		{pattern: "**/*.go", name: "source/internal/source.go", want: true},
+		{pattern: "source/**", name: "source/internal/source.go", want: true},
+		{pattern: "source/**/paramgen_*.go", name: "source/paramgen_src.go", want: true},
+		{pattern: "source/**/paramgen_*.go", name: "destination/paramgen_dest.go", want: false},
+	}
+	for _, tc := range testCases {
+		if got := matchGlob(tc.pattern, tc.name); got != tc.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tc.pattern, tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestInsertAfterIsIdempotent(t *testing.T) {
+	content := "generate:\n\tgo generate ./...\n\nlint:\n"
+	want := "generate:\n\tgo generate ./...\n\tconn-sdk-cli readmegen -w\n\nlint:\n"
+
+	got := insertAfter(content, "go generate ./...", "\n\tconn-sdk-cli readmegen -w")
+	if got != want {
+		t.Fatalf("unexpected result:\n%s", got)
+	}
+	if got = insertAfter(got, "go generate ./...", "\n\tconn-sdk-cli readmegen -w"); got != want {
+		t.Fatalf("second insert changed the result:\n%s", got)
+	}
+}
+
+func TestLoadRecipeInvalid(t *testing.T) {
+	testCases := map[string]string{
+		"no steps":        "name: Foo\n",
+		"two actions":     "name: Foo\nsteps:\n  - files: Makefile\n    delete: true\n    replace: {old: a, new: b}\n",
+		"no files":        "name: Foo\nsteps:\n  - delete: true\n",
+		"invalid regex":   "name: Foo\nsteps:\n  - files: Makefile\n    regex: {pattern: '(', replace: ''}\n",
+		"missing tmpl":    "name: Foo\nsteps:\n  - writeTemplate: {path: a, templateFile: missing}\n",
+		"invalid mode":    "name: Foo\nsteps:\n  - writeTemplate: {path: a, template: b, mode: '999'}\n",
+		"files and write": "name: Foo\nsteps:\n  - files: a\n    writeTemplate: {path: a, template: b}\n",
+	}
+	for name, recipe := range testCases {
+		t.Run(name, func(t *testing.T) {
+			fsys := fstest.MapFS{"recipe.yaml": {Data: []byte(recipe)}}
+			if _, err := LoadRecipe(fsys, "recipe.yaml"); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
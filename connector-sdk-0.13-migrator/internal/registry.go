@@ -0,0 +1,200 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// MigrationSet is a list of migrators that upgrade a connector from one SDK
+// version to the next.
+type MigrationSet struct {
+	// From is the lowest SDK version the set migrates from.
+	From string
+	// To is the SDK version the set migrates to. The set applies to all
+	// versions from From up to, but not including, To.
+	To        string
+	Migrators []Migrator
+}
+
+func (s MigrationSet) String() string {
+	return s.From + " -> " + s.To
+}
+
+// applies reports whether the set migrates connectors on the given version.
+func (s MigrationSet) applies(version string) bool {
+	return semver.Compare(s.From, version) <= 0 && semver.Compare(version, s.To) < 0
+}
+
+// Registry holds the migration sets, ordered by version.
+type Registry struct {
+	sets []MigrationSet
+}
+
+// DefaultRegistry returns a registry with all migration sets.
+func DefaultRegistry() *Registry {
+	r := &Registry{}
+	r.MustRegister(MigrationSet{
+		From: "v0.12.0",
+		To:   sdkTargetVersion,
+		Migrators: []Migrator{
+			ToolsGo{},
+			ToolDirectives{},
+			UpgradeSDK{},
+			ConnectorGoMigrator{},
+			UpdateSourceGo{},
+			UpdateDestinationGo{},
+			UpdateMiddleware{},
+			UpdateAcceptanceTests{},
+			UpdateTests{},
+			WriteConnectorYaml{},
+			DeleteParamGen{},
+			DeleteSpecGo{},
+			WorkflowRelease{},
+			mustLoadEmbeddedRecipe("goreleaser.yaml"),
+			mustLoadEmbeddedRecipe("makefile.yaml"),
+			mustLoadEmbeddedRecipe("scripts.yaml"),
+		},
+	})
+	return r
+}
+
+// Register adds a migration set. The version range of the set can't overlap
+// with the range of another set and the names of its migrators need to be
+// unique across all sets, so they can be selected on the command line.
+func (r *Registry) Register(set MigrationSet) error {
+	if !semver.IsValid(set.From) || !semver.IsValid(set.To) {
+		return fmt.Errorf("migration set %v: invalid version", set)
+	}
+	if semver.Compare(set.From, set.To) >= 0 {
+		return fmt.Errorf("migration set %v: from version needs to be lower than to version", set)
+	}
+	for _, other := range r.sets {
+		if semver.Compare(set.From, other.To) < 0 && semver.Compare(other.From, set.To) < 0 {
+			return fmt.Errorf("migration set %v overlaps with %v", set, other)
+		}
+	}
+	names := make(map[string]bool)
+	for _, m := range r.Migrators() {
+		names[MigratorName(m)] = true
+	}
+	for _, m := range set.Migrators {
+		if names[MigratorName(m)] {
+			return fmt.Errorf("migration set %v: migrator %s is already registered", set, MigratorName(m))
+		}
+		names[MigratorName(m)] = true
+	}
+
+	r.sets = append(r.sets, set)
+	slices.SortFunc(r.sets, func(a, b MigrationSet) int {
+		return semver.Compare(a.From, b.From)
+	})
+	return nil
+}
+
+// MustRegister is like Register, but panics on error.
+func (r *Registry) MustRegister(set MigrationSet) {
+	if err := r.Register(set); err != nil {
+		panic(err)
+	}
+}
+
+// Append adds migrators to the end of the latest migration set.
+func (r *Registry) Append(migrators ...Migrator) error {
+	if len(r.sets) == 0 {
+		return errors.New("no migration set registered")
+	}
+	names := make(map[string]bool)
+	for _, m := range r.Migrators() {
+		names[MigratorName(m)] = true
+	}
+	for _, m := range migrators {
+		if names[MigratorName(m)] {
+			return fmt.Errorf("migrator %s is already registered", MigratorName(m))
+		}
+		names[MigratorName(m)] = true
+	}
+
+	last := &r.sets[len(r.sets)-1]
+	last.Migrators = append(slices.Clone(last.Migrators), migrators...)
+	return nil
+}
+
+// Sets returns all registered migration sets, ordered by version.
+func (r *Registry) Sets() []MigrationSet {
+	return slices.Clone(r.sets)
+}
+
+// Migrators returns the migrators of all sets, ordered by version.
+func (r *Registry) Migrators() []Migrator {
+	var migrators []Migrator
+	for _, set := range r.sets {
+		migrators = append(migrators, set.Migrators...)
+	}
+	return migrators
+}
+
+// Plan returns the chain of migration sets that upgrade a connector on the
+// given SDK version to the latest version. The last set that migrated to the
+// given version or before is included as well, so that an interrupted
+// migration can be completed. Its migrators skip themselves if they were
+// already applied.
+func (r *Registry) Plan(version string) ([]MigrationSet, error) {
+	if !semver.IsValid(version) {
+		return nil, fmt.Errorf("invalid SDK version %q", version)
+	}
+
+	var plan []MigrationSet
+	for _, set := range slices.Backward(r.sets) {
+		if semver.Compare(set.To, version) <= 0 {
+			plan = append(plan, set)
+			break
+		}
+	}
+	current := version
+	for {
+		i := slices.IndexFunc(r.sets, func(set MigrationSet) bool { return set.applies(current) })
+		if i == -1 {
+			break
+		}
+		plan = append(plan, r.sets[i])
+		current = r.sets[i].To
+	}
+
+	if len(plan) == 0 && len(r.sets) > 0 && semver.Compare(version, r.sets[0].From) < 0 {
+		return nil, fmt.Errorf("SDK %s is older than the minimum supported version %s, upgrade the connector to SDK %s first", version, r.sets[0].From, r.sets[0].From)
+	}
+	if len(plan) == 0 {
+		return nil, fmt.Errorf("no migration registered for SDK %s, supported versions: %s", version, r.supported())
+	}
+	if last := r.sets[len(r.sets)-1]; semver.Compare(current, last.To) < 0 {
+		return nil, fmt.Errorf("no migration registered from SDK %s to %s, supported versions: %s", current, last.To, r.supported())
+	}
+	return plan, nil
+}
+
+// supported describes the version ranges of all sets.
+func (r *Registry) supported() string {
+	ranges := make([]string, len(r.sets))
+	for i, set := range r.sets {
+		ranges[i] = set.String()
+	}
+	return strings.Join(ranges, ", ")
+}
@@ -0,0 +1,73 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRegistryPlan(t *testing.T) {
+	r := &Registry{}
+	r.MustRegister(MigrationSet{From: "v0.12.0", To: "v0.13.0"})
+	r.MustRegister(MigrationSet{From: "v0.10.0", To: "v0.11.0"})
+	r.MustRegister(MigrationSet{From: "v0.11.0", To: "v0.12.0"})
+
+	testCases := []struct {
+		version string
+		want    []string
+		wantErr string
+	}{
+		{version: "v0.10.3", want: []string{"v0.10.0 -> v0.11.0", "v0.11.0 -> v0.12.0", "v0.12.0 -> v0.13.0"}},
+		{version: "v0.12.0", want: []string{"v0.11.0 -> v0.12.0", "v0.12.0 -> v0.13.0"}},
+		{version: "v0.13.0", want: []string{"v0.12.0 -> v0.13.0"}},
+		{version: "v0.13.1-0.20250101000000-abcdefabcdef", want: []string{"v0.12.0 -> v0.13.0"}},
+		{version: "v0.9.0", wantErr: "older than the minimum supported version v0.10.0"},
+		{version: "main", wantErr: "invalid SDK version"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.version, func(t *testing.T) {
+			plan, err := r.Plan(tc.version)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error %q, got plan %v, %v", tc.wantErr, plan, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, set := range plan {
+				got = append(got, set.String())
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("got plan %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRegistryRegisterOverlap(t *testing.T) {
+	r := &Registry{}
+	r.MustRegister(MigrationSet{From: "v0.12.0", To: "v0.13.0"})
+	if err := r.Register(MigrationSet{From: "v0.11.0", To: "v0.12.1"}); err == nil {
+		t.Error("expected error for overlapping sets")
+	}
+	if err := r.Register(MigrationSet{From: "v0.13.0", To: "v0.14.0", Migrators: []Migrator{ToolsGo{}, ToolsGo{}}}); err == nil {
+		t.Error("expected error for duplicate migrators")
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Report is the machine-readable result of migrating a connector.
+type Report struct {
+	Connector string `json:"connector"`
+	DryRun    bool   `json:"dryRun"`
+	// SDKVersion is the SDK version the connector is upgraded to. Without a
+	// requested version, it's only set if the SDK was upgraded.
+	SDKVersion string `json:"sdkVersion,omitempty"`
+	// Succeeded is true if all migrators succeeded.
+	Succeeded bool `json:"succeeded"`
+	// RolledBack is true if the changes were restored after a failure.
+	RolledBack bool `json:"rolledBack"`
+	// Error is the error the migration failed with.
+	Error string `json:"error,omitempty"`
+	// Skipped is the reason the directory wasn't migrated, e.g. because
+	// it isn't a connector.
+	Skipped   string        `json:"skipped,omitempty"`
+	Migrators []*StepReport `json:"migrators"`
+	// Verification is the result of verifying the migrated connector, if
+	// it was verified.
+	Verification *Verification `json:"verification,omitempty"`
+}
+
+// StepResult is the outcome of a single migrator.
+type StepResult string
+
+const (
+	StepMigrated       StepResult = "migrated"
+	StepAlreadyApplied StepResult = "already applied"
+	StepNotApplicable  StepResult = "not applicable"
+	StepFailed         StepResult = "failed"
+)
+
+// StepReport describes what a single migrator did.
+type StepReport struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Result      StepResult `json:"result"`
+	Error       string     `json:"error,omitempty"`
+
+	Created  []string `json:"created"`
+	Modified []string `json:"modified"`
+	Deleted  []string `json:"deleted"`
+
+	// Notes describe what the migrator changed, e.g. which types were
+	// migrated.
+	Notes    []string        `json:"notes"`
+	Warnings []string        `json:"warnings"`
+	TODOs    []TODO          `json:"todos"`
+	Commands []CommandResult `json:"commands"`
+	// Rejected are the hunks rejected in an interactive review.
+	Rejected []RejectedHunk `json:"rejected"`
+}
+
+// TODO is a comment left in the code that needs to be resolved manually.
+type TODO struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// CommandResult is the outcome of an external command run by a migrator.
+type CommandResult struct {
+	Dir      string `json:"dir"`
+	Command  string `json:"command"`
+	ExitCode int    `json:"exitCode"`
+	Error    string `json:"error,omitempty"`
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+}
+
+func newStepReport(m Migrator) *StepReport {
+	return &StepReport{
+		Name:        MigratorName(m),
+		Description: m.Description(),
+		Created:     []string{},
+		Modified:    []string{},
+		Deleted:     []string{},
+		Notes:       []string{},
+		Warnings:    []string{},
+		TODOs:       []TODO{},
+		Commands:    []CommandResult{},
+		Rejected:    []RejectedHunk{},
+	}
+}
+
+// Notef records a note about what the migrator changed.
+func (r *StepReport) Notef(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	fmt.Println(msg)
+	r.Notes = append(r.Notes, msg)
+}
+
+// Warnf records a warning that should be looked at after the migration.
+func (r *StepReport) Warnf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	fmt.Printf("warning: %s\n", msg)
+	r.Warnings = append(r.Warnings, msg)
+}
+
+// AddTODOs records every comment in content of file that contains marker as
+// a TODO. The message includes the comment lines following the marker.
+func (r *StepReport) AddTODOs(file string, content []byte, marker string) {
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		if !strings.Contains(line, marker) {
+			continue
+		}
+		msg := []string{commentText(line)}
+		for _, next := range lines[i+1:] {
+			if !strings.HasPrefix(strings.TrimSpace(next), "//") || strings.Contains(next, marker) {
+				break
+			}
+			msg = append(msg, commentText(next))
+		}
+		r.TODOs = append(r.TODOs, TODO{
+			File:    file,
+			Line:    i + 1,
+			Message: strings.Join(msg, " "),
+		})
+	}
+}
+
+// relocateTODOs updates the lines of all TODOs to their position in fsys,
+// since later migrators might have moved them. TODOs that can't be found
+// anymore keep their original position.
+func (r *Report) relocateTODOs(fsys FS) {
+	for _, step := range r.Migrators {
+		// seen counts the TODOs with the same message per file, so that
+		// the n-th TODO is matched with the n-th occurrence.
+		seen := make(map[TODO]int)
+		for i, todo := range step.TODOs {
+			content, err := fsys.ReadFile(todo.File)
+			if err != nil {
+				continue
+			}
+			key := TODO{File: todo.File, Message: todo.Message}
+			n := seen[key]
+			seen[key]++
+			for j, line := range strings.Split(string(content), "\n") {
+				text := commentText(line)
+				if !strings.HasPrefix(strings.TrimSpace(line), "//") || text == "" || !strings.HasPrefix(todo.Message, text) {
+					continue
+				}
+				if n == 0 {
+					step.TODOs[i].Line = j + 1
+					break
+				}
+				n--
+			}
+		}
+	}
+}
+
+// commentText returns the text of a line comment.
+func commentText(line string) string {
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "//"))
+}
+
+// setChanges records the changed files.
+func (r *StepReport) setChanges(changes []Change) {
+	for _, c := range changes {
+		switch c.Op {
+		case ChangeCreated:
+			r.Created = append(r.Created, c.Path)
+		case ChangeModified:
+			r.Modified = append(r.Modified, c.Path)
+		case ChangeDeleted:
+			r.Deleted = append(r.Deleted, c.Path)
+		}
+	}
+}
+
+type stepReportKey struct{}
+
+// withStepReport returns a context that carries the report of the running
+// migrator.
+func withStepReport(ctx context.Context, r *StepReport) context.Context {
+	return context.WithValue(ctx, stepReportKey{}, r)
+}
+
+// StepReportFromContext returns the report of the running migrator. If the
+// context doesn't carry a report, a report that isn't written anywhere is
+// returned, so migrators can always record to it.
+func StepReportFromContext(ctx context.Context) *StepReport {
+	if r, ok := ctx.Value(stepReportKey{}).(*StepReport); ok {
+		return r
+	}
+	return &StepReport{}
+}
+
+// WriteFile writes the report as indented JSON to the named file.
+func (r *Report) WriteFile(name string) error {
+	return writeJSON(name, r)
+}
+
+// writeJSON writes v as indented JSON to the named file.
+func writeJSON(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	if err := os.WriteFile(name, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
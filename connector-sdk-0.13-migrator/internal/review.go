@@ -0,0 +1,239 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// Review asks the user whether each hunk changed by a migrator should be
+// applied.
+type Review struct {
+	in  *bufio.Reader
+	out io.Writer
+	// editor is the command used to edit a hunk.
+	editor string
+}
+
+// NewReview returns a review that reads the answers from in and prints the
+// hunks to out. Hunks are edited with the command in $EDITOR, or vi if it's
+// not set.
+func NewReview(in io.Reader, out io.Writer) *Review {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	return &Review{
+		in:     bufio.NewReader(in),
+		out:    out,
+		editor: editor,
+	}
+}
+
+// RejectedHunk is a change proposed by a migrator that was rejected during
+// the review.
+type RejectedHunk struct {
+	File string `json:"file"`
+	Diff string `json:"diff"`
+}
+
+// reviewDecision is the answer given for a single hunk.
+type reviewDecision int
+
+const (
+	reviewAccept reviewDecision = iota
+	reviewReject
+	reviewEdit
+)
+
+const reviewHelp = `y - apply this hunk
+n - don't apply this hunk
+e - edit the new lines of this hunk in $EDITOR
+a - apply this hunk and all remaining hunks of this migrator
+`
+
+// review goes through the changes made through the snapshot and puts back
+// the original lines of every rejected hunk. Rejected hunks are recorded in
+// the step report from ctx.
+func (r *Review) review(ctx context.Context, name string, snapshot *Snapshot) error {
+	changes, err := snapshot.Changes()
+	if err != nil {
+		return err
+	}
+	step := StepReportFromContext(ctx)
+
+	acceptAll := false
+	for _, c := range changes {
+		old, new := splitLines(string(c.Old)), splitLines(string(c.New))
+		hunks := diffHunks(old, new, diffContext)
+		if len(hunks) == 0 {
+			// The file was created or deleted without content.
+			continue
+		}
+
+		replacements := make([][]string, len(hunks))
+		changed := false
+		for i, h := range hunks {
+			decision := reviewAccept
+			if !acceptAll {
+				fmt.Fprintf(r.out, "\n%s: %s (%s)\n%s", name, c.Path, c.Op, h)
+				decision, acceptAll, err = r.ask(i+1, len(hunks))
+				if err != nil {
+					return err
+				}
+			}
+
+			switch decision {
+			case reviewAccept:
+				replacements[i] = hunkLines(h, opDelete)
+			case reviewReject:
+				replacements[i] = hunkLines(h, opInsert)
+				step.Rejected = append(step.Rejected, RejectedHunk{File: c.Path, Diff: h.String()})
+				changed = true
+			case reviewEdit:
+				edited, err := r.edit(c.Path, hunkLines(h, opDelete))
+				if err != nil {
+					return err
+				}
+				replacements[i] = edited
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		if err := r.write(snapshot, c, applyHunks(old, hunks, replacements)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ask prompts for the decision on a hunk until a valid answer is given. It
+// also returns whether all remaining hunks should be accepted.
+func (r *Review) ask(n, total int) (reviewDecision, bool, error) {
+	for {
+		fmt.Fprintf(r.out, "(%d/%d) Apply this hunk [y,n,e,a,?]? ", n, total)
+		answer, err := r.in.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || answer == "") {
+			return 0, false, fmt.Errorf("failed reading answer: %w", err)
+		}
+		switch strings.TrimSpace(answer) {
+		case "y":
+			return reviewAccept, false, nil
+		case "n":
+			return reviewReject, false, nil
+		case "e":
+			return reviewEdit, false, nil
+		case "a":
+			return reviewAccept, true, nil
+		default:
+			fmt.Fprint(r.out, reviewHelp)
+		}
+	}
+}
+
+// edit opens lines in the editor and returns the edited lines.
+func (r *Review) edit(file string, lines []string) ([]string, error) {
+	f, err := os.CreateTemp("", "hunk-*"+path.Ext(file))
+	if err != nil {
+		return nil, fmt.Errorf("failed creating file for editing: %w", err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(strings.Join(lines, ""))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed writing file for editing: %w", err)
+	}
+
+	// The editor may contain arguments, e.g. "code --wait".
+	args := append(strings.Fields(r.editor), f.Name())
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor %q failed: %w", r.editor, err)
+	}
+
+	edited, err := os.ReadFile(f.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed reading edited file: %w", err)
+	}
+	return splitLines(string(edited)), nil
+}
+
+// write stores the reviewed content of a changed file. A created file that
+// ends up empty is removed again and a deleted file with content is put
+// back.
+func (r *Review) write(snapshot *Snapshot, c Change, lines []string) error {
+	content := strings.Join(lines, "")
+	if c.Op == ChangeCreated && content == "" {
+		return snapshot.Remove(c.Path)
+	}
+	if c.Op == ChangeDeleted && content == "" {
+		return nil
+	}
+
+	perm := snapshot.originals[path.Clean(c.Path)].perm
+	if info, err := snapshot.Stat(c.Path); err == nil {
+		perm = info.Mode().Perm()
+	} else if perm == 0 {
+		perm = 0644
+	}
+	return snapshot.WriteFile(c.Path, []byte(content), perm)
+}
+
+// hunkLines returns the lines of the hunk without the ones of the given
+// kind, i.e. the old lines without opInsert and the new lines without
+// opDelete.
+func hunkLines(h hunk, skip diffOpKind) []string {
+	var lines []string
+	for _, op := range h.ops {
+		if op.kind != skip {
+			lines = append(lines, op.line)
+		}
+	}
+	return lines
+}
+
+// applyHunks replaces the old lines covered by every hunk with the
+// corresponding replacement.
+func applyHunks(old []string, hunks []hunk, replacements [][]string) []string {
+	var lines []string
+	next := 0 // next old line to copy
+	for i, h := range hunks {
+		start := h.oldStart - 1
+		if h.oldLines == 0 {
+			// An empty range starts at the line before the change.
+			start = h.oldStart
+		}
+		lines = append(lines, old[next:start]...)
+		lines = append(lines, replacements[i]...)
+		next = start + h.oldLines
+	}
+	return append(lines, old[next:]...)
+}
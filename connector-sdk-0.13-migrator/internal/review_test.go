@@ -0,0 +1,96 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestReview(t *testing.T) {
+	old := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	testCases := []struct {
+		name        string
+		answers     string
+		want        string
+		wantCreated bool
+		wantReject  int
+	}{{
+		name:        "accept all",
+		answers:     "a\n",
+		want:        "A\nb\nc\nd\ne\nf\ng\nh\ni\nj\nK\n",
+		wantCreated: true,
+	}, {
+		name:        "reject first hunk",
+		answers:     "n\ny\ny\n",
+		want:        "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nK\n",
+		wantCreated: true,
+		wantReject:  1,
+	}, {
+		name:       "reject all",
+		answers:    "x\nn\nn\nn\n",
+		want:       old,
+		wantReject: 3,
+	}, {
+		name:        "edit hunk",
+		answers:     "e\ny\ny\n",
+		want:        "Edited\nb\nc\nd\ne\nf\ng\nh\ni\nj\nK\n",
+		wantCreated: true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := NewMemFS()
+			if err := fsys.WriteFile("file.txt", []byte(old), 0644); err != nil {
+				t.Fatal(err)
+			}
+			snapshot := NewSnapshot(fsys)
+			if err := snapshot.WriteFile("file.txt", []byte(strings.NewReplacer("a", "A", "k", "K").Replace(old)), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := snapshot.WriteFile("new.txt", []byte("new\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			r := NewReview(strings.NewReader(tc.answers), io.Discard)
+			if strings.HasPrefix(tc.answers, "e") {
+				if _, err := exec.LookPath("sed"); err != nil {
+					t.Skip("sed not available")
+				}
+				r.editor = "sed -i s/A/Edited/"
+			}
+			step := &StepReport{}
+			if err := r.review(withStepReport(context.Background(), step), "Test", snapshot); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := fsys.ReadFile("file.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("unexpected content:\n%s", got)
+			}
+			if _, err := fsys.Stat("new.txt"); (err == nil) != tc.wantCreated {
+				t.Errorf("expected new.txt to be created: %v, got error %v", tc.wantCreated, err)
+			}
+			if len(step.Rejected) != tc.wantReject {
+				t.Errorf("expected %d rejected hunks, got %d", tc.wantReject, len(step.Rejected))
+			}
+		})
+	}
+}
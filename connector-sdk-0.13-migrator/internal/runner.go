@@ -0,0 +1,321 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// Runner runs migrators on a connector.
+type Runner struct {
+	Migrators []Migrator
+
+	// DryRun keeps all changes in memory and prints a diff instead of writing
+	// them.
+	DryRun bool
+	// KeepPartial keeps the changes of the successful migrators when a later
+	// one fails, instead of restoring the original files.
+	KeepPartial bool
+	// Git commits the changes of every migrator to a new branch. The working
+	// tree needs to be clean.
+	Git bool
+	// Verify runs go generate, build, vet and test on the migrated connector.
+	// A failed verification doesn't roll back the migration.
+	Verify bool
+	// Review asks before applying each hunk changed by a migrator, if set.
+	Review *Review
+	// Resume continues an interrupted migration at the first migrator that
+	// wasn't completed, as recorded in StateFile. The files tracked in the
+	// state can't have changed since.
+	Resume bool
+	// SDKVersion is the SDK version the connector is upgraded to, see
+	// ResolveSDKVersion. If empty, the latest release is resolved when the SDK
+	// is upgraded. Connectors on a newer version are not migrated.
+	SDKVersion string
+}
+
+// Run migrates the connector in workingDir. The returned report describes
+// what every migrator did, also if the migration failed.
+func (r Runner) Run(ctx context.Context, workingDir string) (*Report, error) {
+	report := &Report{
+		Connector:  workingDir,
+		DryRun:     r.DryRun,
+		SDKVersion: r.SDKVersion,
+		Migrators:  []*StepReport{},
+	}
+	err := r.run(ctx, workingDir, report)
+	if err != nil {
+		report.Error = err.Error()
+	}
+	return report, err
+}
+
+func (r Runner) run(ctx context.Context, workingDir string, report *Report) error {
+	if r.DryRun && r.Git {
+		return errors.New("a dry run can't be committed to git")
+	}
+
+	if info, err := os.Stat(workingDir); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", workingDir)
+	}
+
+	current, err := SDKVersion(NewOSFS(workingDir))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	// Without a requested version, only a newer minor version is known to be
+	// newer than the target version before it's resolved.
+	target := r.SDKVersion
+	if target == "" {
+		target = semver.MajorMinor(sdkTargetVersion)
+	}
+	if current != "" && semver.Compare(current, target) > 0 && semver.MajorMinor(current) != target {
+		return fmt.Errorf("connector requires SDK %s, which is newer than the target version %s", current, target)
+	}
+	ctx = withTargetSDKVersion(ctx, func() (string, error) {
+		version, err := ResolveSDKVersion(ctx, r.SDKVersion)
+		if err != nil {
+			return "", err
+		}
+		fmt.Printf("Upgrading to SDK %s\n", version)
+		report.SDKVersion = version
+		return version, nil
+	})
+
+	fmt.Printf("Migrating %v\n", workingDir)
+
+	state, err := r.loadState(workingDir)
+	if err != nil {
+		return err
+	}
+	// The state is kept out of the snapshot, so that it isn't removed when
+	// the changes are rolled back. A rolled back migration can be resumed
+	// from the checkpoint it started at.
+	stateFS := NewOSFS(workingDir)
+	initial := &State{OriginalBranch: state.OriginalBranch, Completed: slices.Clone(state.Completed)}
+
+	var git Git
+	createdBranch := false
+	if r.Git {
+		git = NewGit(workingDir)
+		if err := git.EnsureClean(StateFile); err != nil {
+			return err
+		}
+		branch, err := git.CurrentBranch()
+		if err != nil {
+			return err
+		}
+		switch {
+		// A migration rolled back before completing any migrator left the
+		// original branch checked out.
+		case !r.Resume || len(state.Completed) == 0 && branch != MigrationBranch:
+			if err := git.CreateBranch(MigrationBranch); err != nil {
+				return err
+			}
+			state.OriginalBranch = branch
+			initial.OriginalBranch = branch
+			createdBranch = true
+		case branch != MigrationBranch:
+			return fmt.Errorf("the interrupted migration needs to be resumed on branch %s, currently on %s", MigrationBranch, branch)
+		}
+	}
+
+	// In a dry run all changes are kept in memory. Otherwise, the original
+	// files are kept, so they can be restored if a migrator fails.
+	var fsys FS = NewOSFS(workingDir)
+	var overlay *Overlay
+	var snapshot *Snapshot
+	if r.DryRun {
+		overlay = NewOverlay(fsys)
+		fsys = overlay
+	} else {
+		snapshot = NewSnapshot(fsys)
+		fsys = snapshot
+	}
+
+	resuming := r.Resume
+	for _, m := range r.Migrators {
+		name := MigratorName(m)
+		step := newStepReport(m)
+		report.Migrators = append(report.Migrators, step)
+
+		// Migrators completed before the migration was interrupted are
+		// skipped, up to the first one that wasn't completed.
+		if resuming = resuming && state.completed(name); resuming {
+			fmt.Printf("Skipping %s: completed before the migration was interrupted\n-----------\n", name)
+			step.Result = StepAlreadyApplied
+			continue
+		}
+
+		// Migrators that were already applied or don't apply to this
+		// connector are skipped, so that the migration can be re-run.
+		status, err := m.Check(fsys)
+		if err == nil && status != StatusNeedsMigration {
+			fmt.Printf("Skipping %s: %v\n-----------\n", name, status)
+			step.Result = StepAlreadyApplied
+			if status == StatusNotApplicable {
+				step.Result = StepNotApplicable
+			}
+			if err := r.checkpoint(stateFS, state, name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fmt.Printf("Running %s\n\n", name)
+
+		if err == nil {
+			err = r.runStep(withStepReport(ctx, step), m, fsys, git)
+		} else {
+			err = fmt.Errorf("%s check failed: %w", name, err)
+		}
+		if err != nil {
+			step.Result = StepFailed
+			step.Error = err.Error()
+			if snapshot != nil && !r.KeepPartial {
+				fmt.Printf("\n%s failed, restoring original files\n", name)
+				if restoreErr := r.restore(snapshot, git, state.OriginalBranch, createdBranch); restoreErr != nil {
+					err = errors.Join(err, fmt.Errorf("failed to restore original files: %w", restoreErr))
+				} else {
+					report.RolledBack = true
+					if stateErr := initial.write(stateFS); stateErr != nil {
+						err = errors.Join(err, stateErr)
+					}
+				}
+			}
+			return err
+		}
+		step.Result = StepMigrated
+		if err := r.checkpoint(stateFS, state, name); err != nil {
+			return err
+		}
+
+		fmt.Printf("\nDone with %s\n-----------\n", name)
+	}
+
+	if overlay != nil {
+		if err := overlay.WriteDiff(os.Stdout); err != nil {
+			return fmt.Errorf("failed writing diff: %w", err)
+		}
+	}
+	if !r.DryRun {
+		if err := stateFS.Remove(StateFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", StateFile, err)
+		}
+	}
+	report.relocateTODOs(fsys)
+
+	if r.Verify {
+		fmt.Printf("Verifying %v\n\n", workingDir)
+		report.Verification = verify(ctx, fsys)
+		if err := report.Verification.WriteSummary(os.Stdout); err != nil {
+			return err
+		}
+	}
+	report.Succeeded = true
+	return nil
+}
+
+// runStep runs a single migrator, records its changes in the step report
+// from ctx and commits them if needed.
+func (r Runner) runStep(ctx context.Context, m Migrator, fsys FS, git Git) error {
+	step := NewSnapshot(fsys)
+	migrateErr := m.Migrate(ctx, step)
+	if migrateErr == nil && r.Review != nil {
+		if err := r.Review.review(ctx, MigratorName(m), step); err != nil {
+			return fmt.Errorf("failed reviewing changes of %s: %w", MigratorName(m), err)
+		}
+	}
+
+	changes, err := step.Changes()
+	if err != nil {
+		return errors.Join(migrateErr, err)
+	}
+	StepReportFromContext(ctx).setChanges(changes)
+	if migrateErr != nil {
+		return fmt.Errorf("%s failed: %w", MigratorName(m), migrateErr)
+	}
+
+	if r.Git {
+		if err := git.Commit(m.Description(), changes); err != nil {
+			return fmt.Errorf("failed to commit changes of %s: %w", MigratorName(m), err)
+		}
+	}
+	return nil
+}
+
+// restore rolls back all changes of this run. When using git, the migration
+// branch is deleted too if it was created in this run. A resumed migration
+// is rolled back to its last checkpoint.
+func (r Runner) restore(snapshot *Snapshot, git Git, originalBranch string, createdBranch bool) error {
+	if err := snapshot.Restore(); err != nil {
+		return err
+	}
+	if r.Git && createdBranch {
+		return git.Abandon(originalBranch, MigrationBranch)
+	}
+	return nil
+}
+
+// loadState returns the state of the interrupted migration when resuming,
+// or a new state otherwise. A migration can't be resumed if the files
+// changed since it was interrupted.
+func (r Runner) loadState(workingDir string) (*State, error) {
+	fsys := NewOSFS(workingDir)
+	if !r.Resume {
+		// The state of a migration rolled back before completing any
+		// migrator doesn't need to be resumed.
+		if state, err := loadState(fsys); err == nil && len(state.Completed) > 0 {
+			return nil, fmt.Errorf("found %s of an interrupted migration, use --resume to continue it or remove the file to start over", StateFile)
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		return &State{}, nil
+	}
+
+	state, err := loadState(fsys)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no interrupted migration to resume, %s not found", StateFile)
+	} else if err != nil {
+		return nil, err
+	}
+	changed, err := state.changedFiles(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if len(changed) > 0 {
+		return nil, fmt.Errorf("can't resume the migration, files changed since it was interrupted:\n  %s", strings.Join(changed, "\n  "))
+	}
+	return state, nil
+}
+
+// checkpoint records the completed migrator in the state file. Nothing is
+// recorded in a dry run.
+func (r Runner) checkpoint(fsys FS, state *State, name string) error {
+	if r.DryRun {
+		return nil
+	}
+	return state.checkpoint(fsys, name)
+}
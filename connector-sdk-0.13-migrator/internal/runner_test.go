@@ -0,0 +1,146 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fileMigrator creates a file, and fails as long as fail is set.
+type fileMigrator struct {
+	name string
+	fail *bool
+}
+
+func (m fileMigrator) Description() string {
+	return "Create " + m.name
+}
+
+func (m fileMigrator) Check(fsys FS) (Status, error) {
+	if exists, err := fileExists(fsys, m.name); err != nil || exists {
+		return StatusAlreadyApplied, err
+	}
+	return StatusNeedsMigration, nil
+}
+
+func (m fileMigrator) Migrate(_ context.Context, fsys FS) error {
+	if err := fsys.WriteFile(m.name, []byte(m.name), 0644); err != nil {
+		return err
+	}
+	if m.fail != nil && *m.fail {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func TestRunnerResumeAfterRollback(t *testing.T) {
+	dir := t.TempDir()
+	fail := true
+	runner := Runner{
+		Migrators: []Migrator{fileMigrator{name: "a"}, fileMigrator{name: "b", fail: &fail}},
+	}
+
+	report, err := runner.Run(context.Background(), dir)
+	if err == nil {
+		t.Fatal("expected the migration to fail")
+	}
+	if !report.RolledBack {
+		t.Fatal("expected the changes to be rolled back")
+	}
+	for _, name := range []string{"a", "b"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Fatalf("%s wasn't removed: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, StateFile)); err != nil {
+		t.Fatalf("expected the state to be kept: %v", err)
+	}
+
+	fail = false
+	runner.Resume = true
+	if _, err := runner.Run(context.Background(), dir); err != nil {
+		t.Fatalf("resuming failed: %v", err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("%s wasn't created: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, StateFile)); !os.IsNotExist(err) {
+		t.Fatalf("expected the state to be removed: %v", err)
+	}
+}
+
+func TestRunnerRerunAfterRollback(t *testing.T) {
+	dir := t.TempDir()
+	fail := true
+	runner := Runner{Migrators: []Migrator{fileMigrator{name: "a", fail: &fail}}}
+	if _, err := runner.Run(context.Background(), dir); err == nil {
+		t.Fatal("expected the migration to fail")
+	}
+
+	// Nothing was completed, so the migration can also be started over.
+	fail = false
+	if _, err := runner.Run(context.Background(), dir); err != nil {
+		t.Fatalf("running again failed: %v", err)
+	}
+}
+
+func TestRunnerResolvesSDKVersionLazily(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not available")
+	}
+	// The latest release can't be looked up.
+	t.Setenv("GOPROXY", "off")
+
+	testCases := []struct {
+		name    string
+		version string
+		wantErr bool
+	}{
+		{name: "already upgraded", version: "v0.13.0"},
+		{name: "upgraded", version: "v0.12.0", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			goMod := "module example.com/connector\n\ngo 1.23\n\nrequire " + sdkModule + " " + tc.version + "\n"
+			if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			runner := Runner{Migrators: []Migrator{UpgradeSDK{}}}
+			report, err := runner.Run(context.Background(), dir)
+			if tc.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "failed to resolve latest SDK") {
+					t.Errorf("expected the version to be resolved, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.SDKVersion != "" {
+				t.Errorf("expected no SDK version, got %q", report.SDKVersion)
+			}
+		})
+	}
+}
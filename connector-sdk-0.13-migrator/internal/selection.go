@@ -0,0 +1,162 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MigratorName returns the name used to select a migrator on the command
+// line. It's the name of its type, unless the migrator has a Name method
+// (e.g. recipes).
+func MigratorName(m Migrator) string {
+	if named, ok := m.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return reflect.TypeOf(m).Name()
+}
+
+// Selection describes which migrators should run.
+type Selection struct {
+	// Only runs just the named migrators. All migrators run if it's empty.
+	Only []string
+	// Skip doesn't run the named migrators.
+	Skip []string
+	// From skips all migrators before the named one.
+	From string
+	// Enable runs the named optional migrators, which only run if they're
+	// enabled or listed in Only.
+	Enable []string
+}
+
+// IsOptional reports whether the migrator only runs when it's enabled
+// explicitly.
+func IsOptional(m Migrator) bool {
+	optional, ok := m.(interface{ Optional() bool })
+	return ok && optional.Optional()
+}
+
+// Select returns the migrators from all that match the selection, in the
+// original order. An UnknownMigratorError is returned for every name that
+// doesn't match a migrator.
+func (s Selection) Select(all []Migrator) ([]Migrator, error) {
+	byName := make(map[string]int, len(all))
+	names := make([]string, len(all))
+	for i, m := range all {
+		names[i] = MigratorName(m)
+		byName[strings.ToLower(names[i])] = i
+	}
+
+	var errs []error
+	lookup := func(name string) (int, bool) {
+		i, ok := byName[strings.ToLower(name)]
+		if !ok {
+			errs = append(errs, &UnknownMigratorError{Name: name, Suggestions: suggest(name, names)})
+		}
+		return i, ok
+	}
+
+	start := 0
+	if s.From != "" {
+		if i, ok := lookup(s.From); ok {
+			start = i
+		}
+	}
+	only := make(map[int]bool)
+	for _, name := range s.Only {
+		if i, ok := lookup(name); ok {
+			only[i] = true
+		}
+	}
+	skip := make(map[int]bool)
+	for _, name := range s.Skip {
+		if i, ok := lookup(name); ok {
+			skip[i] = true
+		}
+	}
+	enabled := make(map[int]bool)
+	for _, name := range s.Enable {
+		if i, ok := lookup(name); ok {
+			enabled[i] = true
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	var selected []Migrator
+	for i := start; i < len(all); i++ {
+		if (len(only) > 0 && !only[i]) || skip[i] || (IsOptional(all[i]) && !only[i] && !enabled[i]) {
+			continue
+		}
+		selected = append(selected, all[i])
+	}
+	return selected, nil
+}
+
+// UnknownMigratorError is returned when a migrator name doesn't match any
+// migrator.
+type UnknownMigratorError struct {
+	Name        string
+	Suggestions []string
+}
+
+func (e *UnknownMigratorError) Error() string {
+	msg := fmt.Sprintf("unknown migrator %q", e.Name)
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(", did you mean %v?", strings.Join(e.Suggestions, " or "))
+	}
+	return msg
+}
+
+// suggest returns the names that are similar to name: names containing it,
+// or names that are only a few edits away.
+func suggest(name string, names []string) []string {
+	name = strings.ToLower(name)
+	maxDistance := max(2, len(name)/3)
+
+	var suggestions []string
+	for _, candidate := range names {
+		lower := strings.ToLower(candidate)
+		if strings.Contains(lower, name) || levenshtein(name, lower) <= maxDistance {
+			suggestions = append(suggestions, candidate)
+		}
+	}
+	return suggestions
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,49 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSelectionOptional(t *testing.T) {
+	all := []Migrator{ToolsGo{}, ToolDirectives{}, UpgradeSDK{}}
+	testCases := []struct {
+		name      string
+		selection Selection
+		want      []string
+	}{
+		{name: "default", want: []string{"ToolsGo", "UpgradeSDK"}},
+		{name: "enabled", selection: Selection{Enable: []string{"tooldirectives"}}, want: []string{"ToolsGo", "ToolDirectives", "UpgradeSDK"}},
+		{name: "only", selection: Selection{Only: []string{"ToolDirectives"}}, want: []string{"ToolDirectives"}},
+		{name: "enabled and skipped", selection: Selection{Enable: []string{"ToolDirectives"}, Skip: []string{"ToolDirectives"}}, want: []string{"ToolsGo", "UpgradeSDK"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			selected, err := tc.selection.Select(all)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, m := range selected {
+				got = append(got, MigratorName(m))
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
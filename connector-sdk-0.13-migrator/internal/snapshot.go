@@ -0,0 +1,221 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+)
+
+// Snapshot is an FS that remembers the original state of every file written
+// or removed through it, so that all changes can be rolled back with Restore.
+type Snapshot struct {
+	FS
+
+	originals map[string]original
+	// dirs are the directories created through the snapshot, parents first.
+	dirs []string
+}
+
+type original struct {
+	data    []byte
+	perm    fs.FileMode
+	existed bool
+}
+
+func NewSnapshot(fsys FS) *Snapshot {
+	return &Snapshot{
+		FS:        fsys,
+		originals: make(map[string]original),
+	}
+}
+
+func (s *Snapshot) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := s.save(name); err != nil {
+		return err
+	}
+	return s.FS.WriteFile(name, data, perm)
+}
+
+func (s *Snapshot) Remove(name string) error {
+	if err := s.save(name); err != nil {
+		return err
+	}
+	return s.FS.Remove(name)
+}
+
+// save stores the current state of the file, unless it was already saved.
+func (s *Snapshot) save(name string) error {
+	name = path.Clean(name)
+	if _, ok := s.originals[name]; ok {
+		return nil
+	}
+
+	info, err := s.FS.Stat(name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		s.originals[name] = original{}
+		s.saveDirs(path.Dir(name))
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to snapshot %s: %w", name, err)
+	case info.IsDir():
+		// Writing to or removing a directory fails anyway.
+		return nil
+	}
+
+	data, err := s.FS.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot %s: %w", name, err)
+	}
+	s.originals[name] = original{data: data, perm: info.Mode().Perm(), existed: true}
+	return nil
+}
+
+// saveDirs records dir and its parents if they don't exist yet.
+func (s *Snapshot) saveDirs(dir string) {
+	var missing []string
+	for ; dir != "."; dir = path.Dir(dir) {
+		if _, err := s.FS.Stat(dir); err == nil {
+			break
+		}
+		missing = append(missing, dir)
+	}
+	slices.Reverse(missing)
+	s.dirs = append(s.dirs, missing...)
+}
+
+// Restore puts back all files as they were before they were first changed
+// through the snapshot and removes the directories created in the meantime.
+func (s *Snapshot) Restore() error {
+	var errs []error
+	for name, orig := range s.originals {
+		var err error
+		if orig.existed {
+			err = s.restore(name, orig)
+		} else if err = s.FS.Remove(name); errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", name, err))
+		}
+	}
+
+	// Remove the directories in reverse order, children first. A directory
+	// that isn't empty anymore is left in place.
+	for _, dir := range slices.Backward(s.dirs) {
+		if info, err := s.FS.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		_ = s.FS.Remove(dir)
+	}
+
+	clear(s.originals)
+	s.dirs = nil
+	return errors.Join(errs...)
+}
+
+// restore writes back the original file. Writing an existing file keeps its
+// mode, so a file whose mode changed is removed first.
+func (s *Snapshot) restore(name string, orig original) error {
+	if info, err := s.FS.Stat(name); err == nil && info.Mode().Perm() != orig.perm {
+		if err := s.FS.Remove(name); err != nil {
+			return err
+		}
+	}
+	return s.FS.WriteFile(name, orig.data, orig.perm)
+}
+
+// Changes returns the changes made through the snapshot, sorted by path.
+func (s *Snapshot) Changes() ([]Change, error) {
+	var changes []Change
+	for name, orig := range s.originals {
+		data, err := s.FS.ReadFile(name)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if c, ok := newChange(name, orig.data, orig.existed, data, err == nil); ok {
+			changes = append(changes, c)
+		}
+	}
+	sortChanges(changes)
+	return changes, nil
+}
+
+// readFiles returns the state of all regular files in fsys, except for the
+// .git directory, so that changes made directly on disk can be recorded with
+// recordChanges.
+func readFiles(fsys FS) (map[string]original, error) {
+	files := make(map[string]original)
+	err := fsys.WalkDir(".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return fs.SkipDir
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := fsys.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files[p] = original{data: data, perm: info.Mode().Perm(), existed: true}
+		return nil
+	})
+	return files, err
+}
+
+// recordChanges records the files changed on disk since before was read in
+// the snapshots wrapping fsys, as if they were changed through them.
+func recordChanges(fsys FS, before map[string]original) error {
+	s, ok := fsys.(*Snapshot)
+	if !ok {
+		return nil
+	}
+	after, err := readFiles(s.FS)
+	if err != nil {
+		return err
+	}
+	for name, orig := range before {
+		if cur, ok := after[name]; !ok || !bytes.Equal(cur.data, orig.data) || cur.perm != orig.perm {
+			s.remember(name, orig)
+		}
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			s.remember(name, original{})
+		}
+	}
+	return recordChanges(s.FS, before)
+}
+
+// remember stores orig as the original state of the file, unless it was
+// already saved.
+func (s *Snapshot) remember(name string, orig original) {
+	name = path.Clean(name)
+	if _, ok := s.originals[name]; !ok {
+		s.originals[name] = orig
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeTestFiles writes the files to dir with their mode.
+func writeTestFiles(t *testing.T, dir string, files map[string]os.FileMode) {
+	t.Helper()
+	for name, perm := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(name), perm); err != nil {
+			t.Fatal(err)
+		}
+		// Don't depend on the umask.
+		if err := os.Chmod(p, perm); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// checkTestFiles checks that dir contains exactly the files written by
+// writeTestFiles, with their original content and mode.
+func checkTestFiles(t *testing.T, dir string, files map[string]os.FileMode) {
+	t.Helper()
+	var found []string
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		name, _ := filepath.Rel(dir, p)
+		name = filepath.ToSlash(name)
+		found = append(found, name)
+
+		perm, ok := files[name]
+		if !ok {
+			t.Errorf("unexpected file %s", name)
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if string(data) != name {
+			t.Errorf("expected %s to contain %q, got %q", name, name, data)
+		}
+		if info.Mode().Perm() != perm {
+			t.Errorf("expected %s to have mode %v, got %v", name, perm, info.Mode().Perm())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != len(files) {
+		t.Errorf("expected %d files, got %v", len(files), found)
+	}
+}
+
+var snapshotTestFiles = map[string]os.FileMode{
+	"go.mod":             0644,
+	"source/source.go":   0644,
+	"scripts/release.sh": 0755,
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, snapshotTestFiles)
+	snapshot := NewSnapshot(NewOSFS(dir))
+
+	// Modified twice, only the first original counts.
+	for _, content := range []string{"module b\n", "module c\n"} {
+		if err := snapshot.WriteFile("go.mod", []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := snapshot.Remove("scripts/release.sh"); err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshot.WriteFile("dest/spec/spec.go", []byte("package spec\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Created and removed again.
+	if err := snapshot.WriteFile("tmp", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshot.Remove("tmp"); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := snapshot.Changes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range changes {
+		got = append(got, string(c.Op)+" "+c.Path)
+	}
+	want := []string{
+		"created dest/spec/spec.go",
+		"modified go.mod",
+		"deleted scripts/release.sh",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected changes %v, got %v", want, got)
+	}
+
+	if err := snapshot.Restore(); err != nil {
+		t.Fatal(err)
+	}
+	checkTestFiles(t, dir, snapshotTestFiles)
+	if _, err := os.Stat(filepath.Join(dir, "dest")); !os.IsNotExist(err) {
+		t.Errorf("expected the created directory to be removed, got %v", err)
+	}
+	if changes, err := snapshot.Changes(); err != nil || len(changes) != 0 {
+		t.Errorf("expected no changes after restoring, got %v, %v", changes, err)
+	}
+}
+
+func TestSnapshotRestoreKeepsDirectories(t *testing.T) {
+	dir := t.TempDir()
+	snapshot := NewSnapshot(NewOSFS(dir))
+
+	if err := snapshot.WriteFile("dest/dest.go", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Written directly, so it isn't restored and dest isn't empty.
+	writeTestFiles(t, dir, map[string]os.FileMode{"dest/other.go": 0644})
+
+	if err := snapshot.Restore(); err != nil {
+		t.Fatal(err)
+	}
+	checkTestFiles(t, dir, map[string]os.FileMode{"dest/other.go": 0644})
+}
+
+func TestReadFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, snapshotTestFiles)
+	writeTestFiles(t, dir, map[string]os.FileMode{".git/HEAD": 0644})
+	if err := os.Symlink("go.mod", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := readFiles(NewOSFS(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(snapshotTestFiles) {
+		t.Errorf("expected %d files, got %d", len(snapshotTestFiles), len(files))
+	}
+	for name, perm := range snapshotTestFiles {
+		f, ok := files[name]
+		switch {
+		case !ok:
+			t.Errorf("expected %s to be read", name)
+		case !f.existed || string(f.data) != name || f.perm != perm:
+			t.Errorf("unexpected state of %s: %+v", name, f)
+		}
+	}
+}
+
+func TestRecordChanges(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, snapshotTestFiles)
+	inner := NewSnapshot(NewOSFS(dir))
+	outer := NewSnapshot(inner)
+
+	// Already changed through the snapshots before the command ran.
+	if err := outer.WriteFile("source/source.go", []byte("package source\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := readFiles(inner.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Changes made on disk, e.g. by go mod tidy.
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(dir, "scripts/release.sh"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "source/source.go")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.sum"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := recordChanges(outer, before); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := outer.Changes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range changes {
+		got = append(got, string(c.Op)+" "+c.Path)
+	}
+	want := []string{
+		"modified go.mod",
+		"created go.sum",
+		"deleted source/source.go",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected changes %v, got %v", want, got)
+	}
+
+	// Both snapshots restore the files as they were before the first change.
+	for _, s := range []*Snapshot{outer, inner} {
+		if err := s.Restore(); err != nil {
+			t.Fatal(err)
+		}
+		checkTestFiles(t, dir, snapshotTestFiles)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+)
+
+// StateFile is the checkpoint written to the connector while it's being
+// migrated. It's removed once the migration succeeds.
+const StateFile = ".sdk-migration-state.json"
+
+// State records the progress of a migration, so that an interrupted
+// migration can be resumed.
+type State struct {
+	// OriginalBranch is the branch that was checked out before the
+	// migration branch was created, if the migration is committed to git.
+	OriginalBranch string `json:"originalBranch,omitempty"`
+	// Completed are the names of the migrators that ran or were skipped, in
+	// the order they ran.
+	Completed []string `json:"completed"`
+	// Files are the SHA-256 hashes of all files after the last completed
+	// migrator.
+	Files map[string]string `json:"files"`
+}
+
+// loadState reads the state file of an interrupted migration.
+func loadState(fsys FS) (*State, error) {
+	data, err := fsys.ReadFile(StateFile)
+	if err != nil {
+		return nil, err
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", StateFile, err)
+	}
+	return &s, nil
+}
+
+// checkpoint records that the migrator completed and writes the state with
+// the current file hashes to fsys.
+func (s *State) checkpoint(fsys FS, name string) error {
+	s.Completed = append(s.Completed, name)
+	return s.write(fsys)
+}
+
+// write writes the state with the current file hashes to fsys.
+func (s *State) write(fsys FS) error {
+	files, err := hashFiles(fsys)
+	if err != nil {
+		return fmt.Errorf("failed to hash files: %w", err)
+	}
+	s.Files = files
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := fsys.WriteFile(StateFile, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", StateFile, err)
+	}
+	return nil
+}
+
+// completed reports whether the migrator was completed.
+func (s *State) completed(name string) bool {
+	return slices.Contains(s.Completed, name)
+}
+
+// changedFiles returns the tracked files that were changed or removed since
+// the last checkpoint, sorted by path.
+func (s *State) changedFiles(fsys FS) ([]string, error) {
+	files, err := hashFiles(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash files: %w", err)
+	}
+	var changed []string
+	for _, name := range slices.Sorted(maps.Keys(s.Files)) {
+		if files[name] != s.Files[name] {
+			changed = append(changed, name)
+		}
+	}
+	return changed, nil
+}
+
+// hashFiles returns the SHA-256 hashes of all files in fsys, except for the
+// state file and the .git directory.
+func hashFiles(fsys FS) (map[string]string, error) {
+	files := make(map[string]string)
+	err := fsys.WalkDir(".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if p == StateFile {
+			return nil
+		}
+		data, err := fsys.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		files[p] = hex.EncodeToString(sum[:])
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return files, nil
+}
@@ -0,0 +1,59 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStateChangedFiles(t *testing.T) {
+	fsys := NewMemFS()
+	for _, name := range []string{"go.mod", "connector.go", "source/source.go"} {
+		if err := fsys.WriteFile(name, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var state State
+	if err := state.checkpoint(fsys, "ToolsGo"); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadState(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.completed("ToolsGo") || len(loaded.Files) != 3 {
+		t.Fatalf("unexpected state: %+v", loaded)
+	}
+
+	// New files aren't tracked, only changed and removed ones are reported.
+	if err := fsys.WriteFile("connector.go", []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.Remove("source/source.go"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.WriteFile("new.go", []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := loaded.changedFiles(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"connector.go", "source/source.go"}; !slices.Equal(changed, want) {
+		t.Errorf("expected changed files %v, got %v", want, changed)
+	}
+}
@@ -0,0 +1,18 @@
+//go:generate conn-sdk-cli specgen
+
+// Package example implements an example connector.
+package example
+
+import (
+	_ "embed"
+
+	conduit "github.com/conduitio/conduit-connector-sdk"
+)
+
+//go:embed connector.yaml
+var specs string
+
+var version = "(devel)"
+
+// Connector combines all constructors of the connector.
+var Connector = conduit.Connector{NewSpecification: conduit.YAMLSpecification(specs, version), NewSource: NewSource}
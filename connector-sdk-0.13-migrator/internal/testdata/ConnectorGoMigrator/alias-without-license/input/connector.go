@@ -0,0 +1,7 @@
+// Package example implements an example connector.
+package example
+
+import conduit "github.com/conduitio/conduit-connector-sdk"
+
+// Connector combines all constructors of the connector.
+var Connector = conduit.Connector{NewSpecification: Specification, NewSource: NewSource}
@@ -0,0 +1,27 @@
+//go:build !wasm
+
+//go:generate conn-sdk-cli specgen
+
+package example
+
+import (
+	"embed"
+	"fmt"
+
+	"github.com/conduitio/conduit-connector-example/source"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+//go:embed testdata
+var testdata embed.FS
+
+//go:embed connector.yaml
+var specs string
+
+func NewConnector() sdk.Connector {
+	fmt.Println("creating connector")
+	return sdk.Connector{
+		NewSpecification: sdk.YAMLSpecification(specs, version),
+		NewSource:        source.NewSource,
+	}
+}
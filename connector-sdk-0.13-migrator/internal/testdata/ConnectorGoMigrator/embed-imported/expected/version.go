@@ -0,0 +1,4 @@
+package example
+
+// version is set during the build process with ldflags.
+var version = "(devel)"
@@ -0,0 +1,21 @@
+//go:build !wasm
+
+package example
+
+import (
+	"embed"
+	"fmt"
+
+	"github.com/conduitio/conduit-connector-example/source"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+//go:embed testdata
+var testdata embed.FS
+
+func NewConnector() sdk.Connector {
+	fmt.Println("creating connector")
+	return sdk.Connector{
+		NewSource: source.NewSource,
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright © 2024 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destination
+
+//go:generate paramgen -output=paramgen_dest.go Config
+
+import (
+	"context"
+
+	"github.com/conduitio/conduit-commons/config"
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+type Destination struct {
+	sdk.UnimplementedDestination
+
+	config Config
+}
+
+type Config struct {
+	// URL is the address of the service.
+	URL string `json:"url" validate:"required"`
+}
+
+func NewDestination() sdk.Destination {
+	return sdk.DestinationWithMiddleware(&Destination{}, sdk.DefaultDestinationMiddleware()...)
+}
+
+func (d *Destination) Parameters() config.Parameters {
+	return d.config.Parameters()
+}
+
+func (d *Destination) Configure(ctx context.Context, cfg config.Config) error {
+	return sdk.Util.ParseConfig(ctx, cfg, &d.config, NewDestination().Parameters())
+}
+
+func (d *Destination) Open(_ context.Context) error {
+	return nil
+}
+
+func (d *Destination) Write(_ context.Context, records []opencdc.Record) (int, error) {
+	return len(records), nil
+}
+
+func (d *Destination) Teardown(_ context.Context) error {
+	return nil
+}
@@ -0,0 +1,15 @@
+module github.com/conduitio/conduit-connector-example
+
+go 1.24.0
+
+require (
+	github.com/conduitio/conduit-connector-sdk v0.13.0
+	github.com/golangci/golangci-lint v1.63.4
+	go.uber.org/mock v0.5.0
+)
+
+tool (
+	github.com/conduitio/conduit-connector-sdk/conn-sdk-cli
+	github.com/golangci/golangci-lint/cmd/golangci-lint
+	go.uber.org/mock/mockgen
+)
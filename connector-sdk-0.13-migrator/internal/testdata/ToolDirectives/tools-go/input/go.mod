@@ -0,0 +1,9 @@
+module github.com/conduitio/conduit-connector-example
+
+go 1.23.2
+
+require (
+	github.com/conduitio/conduit-connector-sdk v0.13.0
+	github.com/golangci/golangci-lint v1.63.4
+	go.uber.org/mock v0.5.0
+)
@@ -0,0 +1,13 @@
+module github.com/conduitio/conduit-connector-example/tools
+
+go 1.24.0
+
+require (
+	github.com/conduitio/conduit-connector-sdk/conn-sdk-cli v0.13.0
+	github.com/golangci/golangci-lint v1.63.4
+)
+
+tool (
+	github.com/conduitio/conduit-connector-sdk/conn-sdk-cli
+	github.com/golangci/golangci-lint/cmd/golangci-lint
+)
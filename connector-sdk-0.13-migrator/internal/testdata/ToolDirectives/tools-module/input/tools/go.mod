@@ -0,0 +1,10 @@
+module github.com/conduitio/conduit-connector-example/tools
+
+go 1.23.2
+
+toolchain go1.23.4
+
+require (
+	github.com/conduitio/conduit-connector-sdk/conn-sdk-cli v0.13.0
+	github.com/golangci/golangci-lint v1.63.4
+)
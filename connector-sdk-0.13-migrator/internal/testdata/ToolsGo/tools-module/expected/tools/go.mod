@@ -0,0 +1,5 @@
+module github.com/conduitio/conduit-connector-example/tools
+
+go 1.23.2
+
+require github.com/conduitio/conduit-commons v0.5.0
@@ -0,0 +1,73 @@
+// Copyright © 2024 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package example
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+type driver struct {
+	sdk.ConfigurableAcceptanceTestDriver
+}
+
+func (d driver) WriteToSource(t *testing.T, records []opencdc.Record) []opencdc.Record {
+	ctx := d.Context()
+	dest := d.Connector().NewDestination()
+	err := sdk.Util.ParseConfig(ctx, d.DestinationConfig(t), dest.Config(), d.Connector().NewSpecification().DestinationParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dest.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dest.Write(ctx, records); err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func (d driver) ReadFromDestination(t *testing.T, records []opencdc.Record) []opencdc.Record {
+	src := newSource(d)
+	if err := src.Configure(context.Background(), d.SourceConfig(t)); err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func newSource(d driver) sdk.Source {
+	return d.Connector().NewSource()
+}
+
+func TestAcceptance(t *testing.T) {
+	cfg := map[string]string{"url": "http://localhost", "sdk.schema.extract.type": "avro"}
+	sdk.AcceptanceTest(t, driver{
+		ConfigurableAcceptanceTestDriver: sdk.ConfigurableAcceptanceTestDriver{
+			Config: sdk.ConfigurableAcceptanceTestDriverConfig{
+				Connector: Connector,
+				SourceConfig: map[string]string{
+					"url":                                "http://localhost",
+					"sdk.schema.extract.payload.subject": "payload",
+					"sdk.schema.extract.key.subject":     "key",
+					"sdk.batch.size":                     "10",
+				},
+				DestinationConfig: cfg,
+			},
+		},
+	})
+}
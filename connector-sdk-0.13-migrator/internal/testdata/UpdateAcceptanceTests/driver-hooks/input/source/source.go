@@ -0,0 +1,57 @@
+// Copyright © 2024 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+
+	"github.com/conduitio/conduit-commons/config"
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+type Source struct {
+	sdk.UnimplementedSource
+
+	config Config
+}
+
+type Config struct {
+	URL string `json:"url" validate:"required"`
+}
+
+func NewSource() sdk.Source {
+	return sdk.SourceWithMiddleware(&Source{})
+}
+
+func (s *Source) Parameters() config.Parameters {
+	return s.config.Parameters()
+}
+
+func (s *Source) Open(context.Context, opencdc.Position) error {
+	return nil
+}
+
+func (s *Source) Read(context.Context) (opencdc.Record, error) {
+	return opencdc.Record{}, nil
+}
+
+func (s *Source) Ack(context.Context, opencdc.Position) error {
+	return nil
+}
+
+func (s *Source) Teardown(context.Context) error {
+	return nil
+}
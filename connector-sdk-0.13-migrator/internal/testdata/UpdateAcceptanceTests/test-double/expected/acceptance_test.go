@@ -0,0 +1,71 @@
+// Copyright © 2024 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package example
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/conduitio/conduit-connector-example/destination"
+	"github.com/conduitio/conduit-connector-example/source"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+// flakyDestination fails every other write, to test the retries.
+type flakyDestination struct {
+	sdk.Destination
+	calls int
+}
+
+func (d *flakyDestination) Write(ctx context.Context, records []opencdc.Record) (int, error) {
+	d.calls++
+	if d.calls%2 == 0 {
+		return 0, context.DeadlineExceeded
+	}
+	return d.Destination.Write(ctx, records)
+}
+
+func newFlakyDestination() sdk.Destination {
+	return &flakyDestination{Destination: destination.NewDestination()}
+}
+
+func TestAcceptance(t *testing.T) {
+	cfg := map[string]string{"url": "http://localhost"}
+	sdk.AcceptanceTest(t, sdk.ConfigurableAcceptanceTestDriver{
+		Config: sdk.ConfigurableAcceptanceTestDriverConfig{
+			Connector: sdk.Connector{
+				NewSpecification: Connector.NewSpecification,
+				NewSource:        source.NewSource,
+				NewDestination:   newFlakyDestination,
+			},
+			SourceConfig:      cfg,
+			DestinationConfig: cfg,
+		},
+	})
+}
+
+func TestAcceptanceSource(t *testing.T) {
+	cfg := map[string]string{"url": "http://localhost"}
+	sdk.AcceptanceTest(t, sdk.ConfigurableAcceptanceTestDriver{
+		Config: sdk.ConfigurableAcceptanceTestDriverConfig{
+			Connector: sdk.Connector{
+				NewSpecification: Connector.NewSpecification,
+				NewSource:        source.NewSource,
+			},
+			SourceConfig: cfg,
+		},
+	})
+}
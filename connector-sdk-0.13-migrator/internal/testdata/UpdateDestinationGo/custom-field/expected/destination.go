@@ -0,0 +1,77 @@
+// Copyright © 2024 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package example
+
+//go:generate paramgen -output=paramgen_dest.go DestinationConfig
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conduitio/conduit-commons/config"
+	"github.com/conduitio/conduit-commons/opencdc"
+	conduit "github.com/conduitio/conduit-connector-sdk"
+)
+
+type Destination struct {
+	conduit.UnimplementedDestination
+
+	// config holds the settings of the client, not the connector
+	// configuration.
+	config map[string]string
+	cfg    *DestinationConfig
+}
+
+func (dst *Destination) Config() conduit.DestinationConfig {
+	if dst.cfg == nil {
+		dst.cfg = &DestinationConfig{}
+	}
+	return dst.cfg
+}
+
+type DestinationConfig struct {
+	// Table is the table to write to.
+	Table string `json:"table" validate:"required"`
+}
+
+func (c *DestinationConfig) Validate(ctx context.Context) error {
+	if c.Table == "system" {
+		return fmt.Errorf("can't write to table %q", c.Table)
+	}
+	return nil
+}
+
+func NewDestination() conduit.Destination {
+	return conduit.DestinationWithMiddleware(&Destination{}, conduit.DefaultDestinationMiddleware()...)
+}
+
+// TODO: This method needs to be removed. If there's any custom logic in Configure(),
+// it needs to be moved to the configuration struct in the Validate() method.
+func (dst *Destination) Configure(ctx context.Context, raw config.Config) error {
+	dst.cfg = &DestinationConfig{}
+	return nil
+}
+
+func (dst *Destination) Open(_ context.Context) error {
+	return nil
+}
+
+func (dst *Destination) Write(_ context.Context, records []opencdc.Record) (int, error) {
+	return len(records), nil
+}
+
+func (dst *Destination) Teardown(_ context.Context) error {
+	return nil
+}
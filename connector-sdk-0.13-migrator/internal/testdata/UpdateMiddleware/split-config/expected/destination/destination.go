@@ -0,0 +1,63 @@
+// Copyright © 2024 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destination
+
+import (
+	"context"
+
+	"github.com/conduitio/conduit-commons/lang"
+	"github.com/conduitio/conduit-commons/opencdc"
+	conduit "github.com/conduitio/conduit-connector-sdk"
+)
+
+type Destination struct {
+	conduit.UnimplementedDestination
+
+	config Config
+}
+
+type Config struct {
+	conduit.DefaultDestinationMiddleware
+
+	// URL is the address of the service.
+	URL string `json:"url" validate:"required"`
+}
+
+func (d *Destination) Config() conduit.DestinationConfig {
+	return &d.config
+}
+
+func NewDestination() conduit.Destination {
+	// TODO: The options of the default middleware need to be migrated.
+	// Set them as defaults of the middleware embedded in the config struct
+	// and remove the arguments.
+	return conduit.DestinationWithMiddleware(&Destination{}, conduit.DefaultDestinationMiddleware(
+		conduit.DestinationWithSchemaExtractionConfig{
+			PayloadEnabled: lang.Ptr(false),
+		},
+	)...)
+}
+
+func (d *Destination) Open(_ context.Context) error {
+	return nil
+}
+
+func (d *Destination) Write(_ context.Context, records []opencdc.Record) (int, error) {
+	return len(records), nil
+}
+
+func (d *Destination) Teardown(_ context.Context) error {
+	return nil
+}
@@ -0,0 +1,39 @@
+// Copyright © 2024 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type Config struct {
+	// URL is the address of the service.
+	URL string `json:"url" validate:"required"`
+	// Tables are the tables to read from.
+	Tables []string `json:"tables"`
+}
+
+func (c *Config) Validate(ctx context.Context) error {
+	// The URL needs a scheme.
+	if !strings.HasPrefix(c.URL, "http") {
+		return fmt.Errorf("invalid URL %q", c.URL)
+	}
+	if err := validateTables(c.Tables); err != nil {
+		return err
+	}
+	return nil
+}
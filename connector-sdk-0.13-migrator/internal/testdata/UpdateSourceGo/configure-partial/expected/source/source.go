@@ -0,0 +1,72 @@
+// Copyright © 2024 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/conduitio/conduit-commons/config"
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+type Source struct {
+	sdk.UnimplementedSource
+
+	config Config
+	client *http.Client
+}
+
+func (s *Source) Config() sdk.SourceConfig {
+	return &s.config
+}
+
+func NewSource() sdk.Source {
+	return sdk.SourceWithMiddleware(&Source{}, sdk.DefaultSourceMiddleware()...)
+}
+
+// TODO: This method needs to be removed. If there's any custom logic in Configure(),
+// it needs to be moved to the configuration struct in the Validate() method.
+func (s *Source) Configure(ctx context.Context, cfg config.Config) error {
+	s.client = &http.Client{}
+	return nil
+}
+
+func validateTables(tables []string) error {
+	for _, table := range tables {
+		if table == "" {
+			return errors.New("table names must not be empty")
+		}
+	}
+	return nil
+}
+
+func (s *Source) Open(_ context.Context, _ opencdc.Position) error {
+	return nil
+}
+
+func (s *Source) Read(_ context.Context) (opencdc.Record, error) {
+	return opencdc.Record{}, sdk.ErrBackoffRetry
+}
+
+func (s *Source) Ack(_ context.Context, _ opencdc.Position) error {
+	return nil
+}
+
+func (s *Source) Teardown(_ context.Context) error {
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright © 2024 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+type Reader struct {
+	sdk.UnimplementedSource
+
+	config Config
+}
+
+func (s *Reader) Config() sdk.SourceConfig {
+	return &s.config
+}
+
+type Config struct {
+	// Query selects the rows of the snapshot.
+	Query string `json:"query" validate:"required"`
+}
+
+func (s *Reader) Open(_ context.Context, _ opencdc.Position) error {
+	return nil
+}
+
+func (s *Reader) Read(_ context.Context) (opencdc.Record, error) {
+	return opencdc.Record{}, sdk.ErrBackoffRetry
+}
+
+func (s *Reader) Ack(_ context.Context, _ opencdc.Position) error {
+	return nil
+}
+
+func (s *Reader) Teardown(_ context.Context) error {
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright © 2024 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"context"
+
+	"github.com/conduitio/conduit-commons/config"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+// Iterator reads changes from the change data capture stream.
+type Iterator struct {
+	sdk.UnimplementedSource
+
+	config Config
+}
+
+type Config struct {
+	// Table is the table to read changes from.
+	Table string `json:"table" validate:"required"`
+}
+
+// Parameters returns the parameters of the iterator.
+func (s *Iterator) Parameters() config.Parameters {
+	return s.config.Parameters()
+}
+
+func (s *Iterator) Configure(ctx context.Context, cfg config.Config) error {
+	return sdk.Util.ParseConfig(ctx, cfg, &s.config, s.Parameters())
+}
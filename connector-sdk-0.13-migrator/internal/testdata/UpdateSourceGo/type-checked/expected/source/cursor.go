@@ -0,0 +1,49 @@
+// Copyright © 2024 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+// Cursor declares the methods of a source, but isn't one: Open doesn't take
+// a position.
+type Cursor struct {
+	config cursorConfig
+}
+
+type cursorConfig struct {
+	table    string
+	position opencdc.Position
+}
+
+func (c *Cursor) Open(_ context.Context) error {
+	return nil
+}
+
+func (c *Cursor) Read(_ context.Context) (opencdc.Record, error) {
+	return opencdc.Record{}, sdk.ErrBackoffRetry
+}
+
+func (c *Cursor) Ack(_ context.Context, _ opencdc.Position) error {
+	return nil
+}
+
+func (c *Cursor) Teardown(_ context.Context) error {
+	return nil
+}
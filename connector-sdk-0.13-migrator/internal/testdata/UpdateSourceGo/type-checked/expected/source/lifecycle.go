@@ -0,0 +1,41 @@
+// Copyright © 2024 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+func (r *Reader) Open(ctx context.Context, pos opencdc.Position) error {
+	r.cursor = &Cursor{config: cursorConfig{table: r.config.Table, position: pos}}
+	return r.cursor.Open(ctx)
+}
+
+func (r *Reader) Read(ctx context.Context) (opencdc.Record, error) {
+	return r.cursor.Read(ctx)
+}
+
+func (r *Reader) Ack(ctx context.Context, pos opencdc.Position) error {
+	return r.cursor.Ack(ctx, pos)
+}
+
+func (r *Reader) Teardown(ctx context.Context) error {
+	if r.cursor == nil {
+		return nil
+	}
+	return r.cursor.Teardown(ctx)
+}
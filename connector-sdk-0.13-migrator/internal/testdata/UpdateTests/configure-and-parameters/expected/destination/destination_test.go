@@ -0,0 +1,44 @@
+// Copyright © 2024 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destination_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/conduitio/conduit-connector-example/destination"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+func TestDestination_Configure(t *testing.T) {
+	dest := destination.NewDestination()
+	if err := sdk.Util.ParseConfig(context.Background(), map[string]string{}, dest.Config(), specification().DestinationParams); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// specification returns the connector specification from ../connector.yaml.
+func specification() sdk.Specification {
+	raw, err := os.ReadFile("../connector.yaml")
+	if err != nil {
+		panic(err)
+	}
+	spec, err := sdk.ParseYAMLSpecification(context.Background(), string(raw), "")
+	if err != nil {
+		panic(err)
+	}
+	return spec
+}
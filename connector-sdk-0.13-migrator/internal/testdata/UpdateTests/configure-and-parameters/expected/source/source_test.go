@@ -0,0 +1,61 @@
+// Copyright © 2024 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	sdk "github.com/conduitio/conduit-connector-sdk"
+)
+
+func TestSource_Configure(t *testing.T) {
+	ctx := context.Background()
+	src := NewSource()
+	err := sdk.Util.ParseConfig(ctx, map[string]string{"url": "http://localhost"}, src.Config(), specification().SourceParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSource_Parameters(t *testing.T) {
+	if len(specification().SourceParams) == 0 {
+		t.Fatal("expected parameters")
+	}
+}
+
+func TestSource_ConfigureHelper(t *testing.T) {
+	if err := newTestSource().Configure(context.Background(), nil); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func newTestSource() *Source {
+	return &Source{}
+}
+
+// specification returns the connector specification from ../connector.yaml.
+func specification() sdk.Specification {
+	raw, err := os.ReadFile("../connector.yaml")
+	if err != nil {
+		panic(err)
+	}
+	spec, err := sdk.ParseYAMLSpecification(context.Background(), string(raw), "")
+	if err != nil {
+		panic(err)
+	}
+	return spec
+}
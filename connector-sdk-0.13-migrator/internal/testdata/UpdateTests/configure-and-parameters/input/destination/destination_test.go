@@ -0,0 +1,29 @@
+// Copyright © 2024 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destination_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conduitio/conduit-connector-example/destination"
+)
+
+func TestDestination_Configure(t *testing.T) {
+	dest := destination.NewDestination()
+	if err := dest.Configure(context.Background(), map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+}
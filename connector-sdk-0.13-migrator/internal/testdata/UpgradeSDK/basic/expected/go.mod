@@ -0,0 +1,8 @@
+module github.com/conduitio/conduit-connector-example
+
+go 1.23.2
+
+require (
+	github.com/conduitio/conduit-commons v0.5.0
+	github.com/conduitio/conduit-connector-sdk v0.13.0
+)
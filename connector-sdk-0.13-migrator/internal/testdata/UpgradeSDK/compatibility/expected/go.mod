@@ -0,0 +1,16 @@
+module github.com/conduitio/conduit-connector-example
+
+go 1.23.2
+
+require (
+	github.com/conduitio/conduit-commons v0.5.0
+	github.com/conduitio/conduit-connector-sdk v0.13.0
+	github.com/matryer/is v1.4.1
+)
+
+require (
+	github.com/conduitio/conduit-connector-protocol v0.9.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+)
+
+replace github.com/google/uuid => github.com/google/uuid v1.5.0
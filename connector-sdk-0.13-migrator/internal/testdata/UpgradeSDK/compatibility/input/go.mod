@@ -0,0 +1,23 @@
+module github.com/conduitio/conduit-connector-example
+
+go 1.22
+
+toolchain go1.22.5
+
+require (
+	github.com/conduitio/conduit-commons v0.4.0
+	github.com/conduitio/conduit-connector-sdk v0.12.0
+	github.com/matryer/is v1.4.1
+)
+
+require (
+	github.com/conduitio/conduit-connector-protocol v0.8.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+)
+
+replace github.com/conduitio/conduit-connector-sdk => ../conduit-connector-sdk
+
+replace (
+	github.com/conduitio/conduit-commons v0.4.0 => github.com/example/conduit-commons v0.4.1
+	github.com/google/uuid => github.com/google/uuid v1.5.0
+)
@@ -0,0 +1,194 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// toolDirectivesGoVersion is the first go version supporting tool
+// directives.
+const toolDirectivesGoVersion = "1.24.0"
+
+// ToolDirectives replaces the blank imports in tools.go with tool directives
+// in go.mod. It's optional, since it requires Go 1.24.
+type ToolDirectives struct {
+}
+
+func (t ToolDirectives) Description() string {
+	return "Replace tools.go with tool directives in go.mod"
+}
+
+func (t ToolDirectives) Optional() bool {
+	return true
+}
+
+func (t ToolDirectives) Check(fsys FS) (Status, error) {
+	toolsGo, _, err := t.find(fsys)
+	if err != nil {
+		return 0, err
+	}
+	if toolsGo != "" {
+		return StatusNeedsMigration, nil
+	}
+
+	for _, name := range []string{"go.mod", "tools/go.mod"} {
+		mod, err := ReadGoMod(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if len(mod.Tools()) > 0 {
+			return StatusAlreadyApplied, nil
+		}
+	}
+	return StatusNotApplicable, nil
+}
+
+func (t ToolDirectives) Migrate(ctx context.Context, fsys FS) error {
+	toolsGo, dir, err := t.find(fsys)
+	if err != nil {
+		return err
+	}
+	content, err := fsys.ReadFile(toolsGo)
+	if err != nil {
+		return fmt.Errorf("failed reading %s: %w", toolsGo, err)
+	}
+	tools, err := t.tools(ctx, toolsGo, content)
+	if err != nil {
+		return err
+	}
+
+	goModPath := path.Join(dir, "go.mod")
+	mod, err := ReadGoMod(fsys, goModPath)
+	if err != nil {
+		return fmt.Errorf("failed reading %s: %w", goModPath, err)
+	}
+	if err := mod.UpgradeGo(toolDirectivesGoVersion, ""); err != nil {
+		return fmt.Errorf("failed upgrading go version in %s: %w", goModPath, err)
+	}
+	for _, tool := range tools {
+		if err := mod.AddTool(tool); err != nil {
+			return fmt.Errorf("failed adding tool %s to %s: %w", tool, goModPath, err)
+		}
+	}
+	if err := mod.Write(fsys); err != nil {
+		return fmt.Errorf("failed writing %s: %w", goModPath, err)
+	}
+
+	if err := fsys.Remove(toolsGo); err != nil {
+		return fmt.Errorf("failed removing %s: %w", toolsGo, err)
+	}
+	if err := t.updateMakefile(ctx, fsys, dir); err != nil {
+		return err
+	}
+
+	// Requirements of the tools are kept by the tool directives.
+	return runGoModTidy(ctx, fsys, dir)
+}
+
+// find returns the path of tools.go and the directory of the go.mod its
+// package belongs to. An empty path is returned if there's no tools.go.
+func (t ToolDirectives) find(fsys FS) (toolsGo, dir string, err error) {
+	for _, name := range []string{"tools.go", "tools/tools.go"} {
+		exists, err := fileExists(fsys, name)
+		if err != nil {
+			return "", "", err
+		}
+		if !exists {
+			continue
+		}
+
+		dir := path.Dir(name)
+		if exists, err := fileExists(fsys, path.Join(dir, "go.mod")); err != nil {
+			return "", "", err
+		} else if !exists {
+			dir = "."
+		}
+		return name, dir, nil
+	}
+	return "", "", nil
+}
+
+// tools returns the packages imported with blank imports in tools.go. Other
+// imports are reported as warnings.
+func (t ToolDirectives) tools(ctx context.Context, name string, content []byte) ([]string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), name, content, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing %s: %w", name, err)
+	}
+
+	var tools []string
+	for _, imp := range f.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing import %s in %s: %w", imp.Path.Value, name, err)
+		}
+		if imp.Name == nil || imp.Name.Name != "_" {
+			StepReportFromContext(ctx).Warnf("%s imports %s, which isn't a tool, the import is dropped", name, importPath)
+			continue
+		}
+		tools = append(tools, importPath)
+	}
+	return tools, nil
+}
+
+// installToolsRegex matches the recipe of the install-tools target in a
+// Makefile.
+var installToolsRegex = regexp.MustCompile(`(?m)^install-tools:.*\n((?:\t.*(?:\n|$))*)`)
+
+// updateMakefile installs the tools with go install in the install-tools
+// target of the Makefile.
+func (t ToolDirectives) updateMakefile(ctx context.Context, fsys FS, dir string) error {
+	makefilePath, makefile, err := readFile(fsys, "Makefile")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed reading Makefile: %w", err)
+	}
+
+	match := installToolsRegex.FindStringSubmatchIndex(makefile)
+	if match == nil {
+		StepReportFromContext(ctx).Warnf("Makefile has no install-tools target, install the tools with `go install tool`")
+		return nil
+	}
+
+	install := "\tgo install tool\n"
+	if dir != "." {
+		install = "\tgo -C " + dir + " install tool\n"
+	}
+	recipe := makefile[match[2]:match[3]]
+	if !strings.HasSuffix(recipe, "\n") {
+		install = strings.TrimSuffix(install, "\n")
+	}
+	updated := makefile[:match[2]] + install + makefile[match[3]:]
+
+	if err := fsys.WriteFile(makefilePath, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed writing Makefile: %w", err)
+	}
+	return nil
+}
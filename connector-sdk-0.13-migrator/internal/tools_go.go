@@ -15,58 +15,109 @@
 package internal
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
+	"go/token"
+	"io/fs"
+	"path"
+	"strconv"
 	"strings"
 )
 
+const (
+	paramgenModule   = "github.com/conduitio/conduit-commons/paramgen"
+	connSDKCLIModule = "github.com/conduitio/conduit-connector-sdk/conn-sdk-cli"
+)
+
 type ToolsGo struct {
 }
 
-func (t ToolsGo) Migrate(workingDir string) error {
-	toolsGoPath, toolsGo, err := readFile(workingDir, "tools.go")
-	if errors.Is(err, os.ErrNotExist) {
-		return t.migrateToolsDir(workingDir)
+func (t ToolsGo) Description() string {
+	return "Replace paramgen with conn-sdk-cli in tools.go"
+}
+
+func (t ToolsGo) Check(fsys FS) (Status, error) {
+	for _, name := range []string{"tools.go", "tools/tools.go", "tools/go.mod"} {
+		_, contents, err := readFile(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		switch {
+		case strings.Contains(contents, paramgenModule):
+			return StatusNeedsMigration, nil
+		case strings.Contains(contents, connSDKCLIModule):
+			return StatusAlreadyApplied, nil
+		}
+		return StatusNotApplicable, nil
 	}
+	return StatusNotApplicable, nil
+}
 
+func (t ToolsGo) Migrate(ctx context.Context, fsys FS) error {
+	exists, err := fileExists(fsys, "tools.go")
 	if err != nil {
 		return err
 	}
+	if !exists {
+		return t.migrateToolsDir(ctx, fsys)
+	}
+	return t.replaceImport(fsys, "tools.go")
+}
 
-	updatedToolsGo := strings.ReplaceAll(
-		toolsGo,
-		"_ \"github.com/conduitio/conduit-commons/paramgen\"",
-		"_ \"github.com/conduitio/conduit-connector-sdk/conn-sdk-cli\"",
-	)
-
-	err = os.WriteFile(toolsGoPath, []byte(updatedToolsGo), 0644)
+// replaceImport replaces the paramgen import in the Go file with
+// conn-sdk-cli.
+func (t ToolsGo) replaceImport(fsys FS, name string) error {
+	f, err := parseGoFile(fsys, token.NewFileSet(), name)
 	if err != nil {
-		return fmt.Errorf("failed writing new contents of tools.go: %w", err)
+		return err
 	}
-
-	return nil
+	edits := newGoEdits()
+	for _, imp := range f.ast.Imports {
+		if importPath, _ := strconv.Unquote(imp.Path.Value); importPath == paramgenModule {
+			edits.add(f, textEdit{
+				start: f.offset(imp.Path.Pos()),
+				end:   f.offset(imp.Path.End()),
+				text:  strconv.Quote(connSDKCLIModule),
+			})
+		}
+	}
+	_, err = edits.write(fsys)
+	return err
 }
 
-func (t ToolsGo) migrateToolsDir(workingDir string) error {
-	toolsGoPath, toolsGo, err := readFile(workingDir, "tools/go.mod")
+func (t ToolsGo) migrateToolsDir(ctx context.Context, fsys FS) error {
+	toolsGoPath, toolsGo, err := readFile(fsys, "tools/go.mod")
 	if err != nil {
 		return fmt.Errorf("failed reading tools/go.mod: %w", err)
 	}
 	updatedGoMod := strings.ReplaceAll(
 		toolsGo,
-		"github.com/conduitio/conduit-commons/paramgen",
-		"github.com/conduitio/conduit-connector-sdk/conn-sdk-cli",
+		paramgenModule,
+		connSDKCLIModule,
 	)
 
-	err = os.WriteFile(toolsGoPath, []byte(updatedGoMod), 0644)
+	err = fsys.WriteFile(toolsGoPath, []byte(updatedGoMod), 0644)
 	if err != nil {
 		return fmt.Errorf("failed writing new contents of tools/go.mod: %w", err)
 	}
 
-	err = runGoModTidy(filepath.Join(workingDir, "tools"))
+	// The tools are usually imported in tools/tools.go.
+	exists, err := fileExists(fsys, "tools/tools.go")
+	if err != nil {
+		return err
+	}
+	if exists {
+		if err := t.replaceImport(fsys, "tools/tools.go"); err != nil {
+			return err
+		}
+	}
+
+	err = runGoModTidy(ctx, fsys, "tools")
 	if err != nil {
 		return fmt.Errorf("failed to run go mod tidy in tools directory: %w", err)
 	}
@@ -74,38 +125,22 @@ func (t ToolsGo) migrateToolsDir(workingDir string) error {
 	return nil
 }
 
-func runGoModTidy(dir string) error {
-	// Convert to absolute path
-	absDir, err := filepath.Abs(dir)
-	if err != nil {
-		return fmt.Errorf("failed to get absolute path: %w", err)
-	}
-
+func runGoModTidy(ctx context.Context, fsys FS, dir string) error {
 	// Check if directory exists
-	if _, err := os.Stat(absDir); os.IsNotExist(err) {
-		return fmt.Errorf("directory does not exist: %s", absDir)
+	if _, err := fsys.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("directory does not exist: %s", dir)
 	}
 
 	// Check if go.mod exists in the directory
-	goModPath := filepath.Join(absDir, "go.mod")
-	if _, err := os.Stat(goModPath); os.IsNotExist(err) {
-		return fmt.Errorf("go.mod not found in directory: %s", absDir)
+	if _, err := fsys.Stat(path.Join(dir, "go.mod")); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("go.mod not found in directory: %s", dir)
 	}
 
-	// Create the command
-	cmd := exec.Command("go", "mod", "tidy")
-	cmd.Dir = absDir
-
-	// Set up output capture
-	output, err := cmd.CombinedOutput()
+	err := runCommand(ctx, fsys, dir, "go", "mod", "tidy")
 	if err != nil {
-		return fmt.Errorf("go mod tidy failed: %w\nOutput: %s", err, string(output))
-	}
-
-	fmt.Printf("Successfully ran 'go mod tidy' in %s\n", absDir)
-	if len(output) > 0 {
-		fmt.Printf("Output: %s\n", string(output))
+		return fmt.Errorf("go mod tidy failed: %w", err)
 	}
 
+	fmt.Printf("Successfully ran 'go mod tidy' in %s\n", dir)
 	return nil
 }
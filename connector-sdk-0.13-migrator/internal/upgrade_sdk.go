@@ -16,39 +16,201 @@ package internal
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
 )
 
+const sdkModule = "github.com/conduitio/conduit-connector-sdk"
+
+// sdkTargetVersion is the first SDK version that doesn't need the migration.
+const sdkTargetVersion = "v0.13.0"
+
 type UpgradeSDK struct {
 }
 
-func (u UpgradeSDK) Migrate(workingDir string) error {
-	module := "github.com/conduitio/conduit-connector-sdk"
-	version := "main"
+func (u UpgradeSDK) Description() string {
+	return "Upgrade conduit-connector-sdk"
+}
 
-	// Run go get command
-	err := runCommand(workingDir, "go", "get", fmt.Sprintf("%s@%s", module, version))
+func (u UpgradeSDK) Check(fsys FS) (Status, error) {
+	version, err := SDKVersion(fsys)
+	if errors.Is(err, fs.ErrNotExist) {
+		return StatusNotApplicable, nil
+	}
 	if err != nil {
-		return fmt.Errorf("could not run `go get`: %w", err)
+		return 0, err
+	}
+	if version == "" {
+		return StatusNotApplicable, nil
+	}
+	if semver.Compare(version, sdkTargetVersion) >= 0 {
+		return StatusAlreadyApplied, nil
 	}
+	return StatusNeedsMigration, nil
+}
 
-	// Run go mod tidy
-	err = runCommand(workingDir, "go", "mod", "tidy")
+// Migrate upgrades the SDK in go.mod, together with the modules and go
+// version it requires according to compatibilityMatrix. Replace directives of
+// the upgraded modules are dropped, since they would pin the old versions.
+// go mod tidy runs once at the end.
+func (u UpgradeSDK) Migrate(ctx context.Context, fsys FS) error {
+	version, err := targetSDKVersion(ctx)
+	if err != nil {
+		return err
+	}
+	compat, err := compatibility(version)
 	if err != nil {
-		return fmt.Errorf("Failed to run go mod tidy: %v\n", err)
+		return err
+	}
+	mod, err := ReadGoMod(fsys, "go.mod")
+	if err != nil {
+		return fmt.Errorf("failed reading go.mod: %w", err)
+	}
+
+	report := StepReportFromContext(ctx)
+	modules := map[string]string{sdkModule: version}
+	for path, v := range compat.Modules {
+		modules[path] = v
 	}
+	for _, path := range slices.Sorted(maps.Keys(modules)) {
+		if path == sdkModule {
+			err = mod.SetRequire(path, version)
+		} else {
+			err = mod.Upgrade(path, modules[path])
+		}
+		if err != nil {
+			return fmt.Errorf("failed upgrading %s: %w", path, err)
+		}
 
+		dropped, err := mod.DropReplaces(path)
+		if err != nil {
+			return fmt.Errorf("failed dropping replace directives of %s: %w", path, err)
+		}
+		for _, r := range dropped {
+			report.Warnf("dropped replace directive %q from go.mod", replaceString(r))
+		}
+	}
+	if err := mod.UpgradeGo(compat.Go, compat.Toolchain); err != nil {
+		return fmt.Errorf("failed upgrading go version: %w", err)
+	}
+	if err := mod.Write(fsys); err != nil {
+		return fmt.Errorf("failed writing go.mod: %w", err)
+	}
+
+	err = runCommand(ctx, fsys, ".", "go", "mod", "tidy")
+	if err != nil {
+		return fmt.Errorf("failed to run go mod tidy: %w", err)
+	}
 	return nil
 }
 
-func runCommand(workingDir string, command string, args ...string) error {
+// ResolveSDKVersion returns the SDK version connectors are upgraded to. The
+// requested version needs to be a release of the same minor version as
+// sdkTargetVersion. If no version is requested, the latest of these releases
+// is looked up through the configured GOPROXY.
+func ResolveSDKVersion(ctx context.Context, requested string) (string, error) {
+	minor := semver.MajorMinor(sdkTargetVersion)
+	if requested != "" {
+		if semver.Canonical(requested) != requested || semver.MajorMinor(requested) != minor {
+			return "", fmt.Errorf("invalid SDK version %q, expected a %s.x version", requested, minor)
+		}
+		return requested, nil
+	}
+
+	// The query runs outside the connector, so its go.mod doesn't matter.
+	cmd := execCommand(ctx, "go", "list", "-m", "-json", sdkModule+"@"+minor)
+	cmd.Dir = os.TempDir()
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve latest SDK %s.x version, use --sdk-version to set it: %w\n%s", minor, err, stderr.String())
+	}
+	var mod struct{ Version string }
+	if err := json.Unmarshal(out, &mod); err != nil {
+		return "", fmt.Errorf("failed to parse output of go list: %w", err)
+	}
+	if semver.MajorMinor(mod.Version) != minor {
+		return "", fmt.Errorf("resolved unexpected SDK version %q", mod.Version)
+	}
+	return mod.Version, nil
+}
+
+type targetSDKVersionKey struct{}
+
+// withTargetSDKVersion returns a context that carries the function resolving
+// the SDK version connectors are upgraded to. It's called at most once, when
+// the version is first needed.
+func withTargetSDKVersion(ctx context.Context, resolve func() (string, error)) context.Context {
+	return context.WithValue(ctx, targetSDKVersionKey{}, sync.OnceValues(resolve))
+}
+
+// targetSDKVersion returns the SDK version connectors are upgraded to, or
+// sdkTargetVersion if the context doesn't carry a resolver.
+func targetSDKVersion(ctx context.Context) (string, error) {
+	if resolve, ok := ctx.Value(targetSDKVersionKey{}).(func() (string, error)); ok {
+		return resolve()
+	}
+	return sdkTargetVersion, nil
+}
+
+// execCommand creates the commands run by runCommand. Tests replace it, so
+// they don't depend on the network.
+var execCommand = exec.CommandContext
+
+// runCommand runs the command in dir, relative to the root of fsys. The
+// command runs in the connector directory, so that relative replace
+// directives keep working, and the files it changes are recorded in the
+// snapshot of the step. If fsys keeps the changes in memory (e.g. in a dry
+// run), the command runs in a temporary copy of the connector instead and
+// the files it changes are written back to fsys. The outcome is recorded in
+// the step report from ctx.
+func runCommand(ctx context.Context, fsys FS, dir string, command string, args ...string) error {
+	root, inPlace := osDir(fsys)
+	var before map[string]original
+	var copied map[string]bool
+	var goMod *copiedGoMod
+	var err error
+	if inPlace {
+		if before, err = readFiles(fsys); err != nil {
+			return fmt.Errorf("failed to read connector files: %w", err)
+		}
+	} else {
+		tmpDir, err := os.MkdirTemp("", "connector-sdk-migrator-")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		copied, err = copyToDir(fsys, tmpDir)
+		if err != nil {
+			return fmt.Errorf("failed to copy connector to %s: %w", tmpDir, err)
+		}
+		// Relative replace directives would point outside of the copy.
+		if goMod, err = resolveReplaces(fsys, dir, tmpDir); err != nil {
+			return err
+		}
+		root = tmpDir
+	}
+
 	// Construct the command
-	cmd := exec.Command(command, args...)
+	cmd := execCommand(ctx, command, args...)
 
 	// Set the working directory
-	cmd.Dir = workingDir
+	cmd.Dir = filepath.Join(root, filepath.FromSlash(dir))
 
 	// Capture stdout and stderr
 	var outBuf, errBuf bytes.Buffer
@@ -59,7 +221,20 @@ func runCommand(workingDir string, command string, args ...string) error {
 	cmd.Env = os.Environ()
 
 	// Run the command
-	err := cmd.Run()
+	err = cmd.Run()
+
+	result := CommandResult{
+		Dir:      dir,
+		Command:  strings.Join(append([]string{command}, args...), " "),
+		ExitCode: cmd.ProcessState.ExitCode(),
+		Stdout:   outBuf.String(),
+		Stderr:   errBuf.String(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	report := StepReportFromContext(ctx)
+	report.Commands = append(report.Commands, result)
 
 	// Print outputs
 	if outBuf.Len() > 0 {
@@ -72,10 +247,44 @@ func runCommand(workingDir string, command string, args ...string) error {
 		fmt.Println(errBuf.String())
 	}
 
+	if inPlace {
+		// A failed command can leave changes behind, they're recorded so
+		// they can be rolled back.
+		if recordErr := recordChanges(fsys, before); recordErr != nil {
+			return errors.Join(err, recordErr)
+		}
+	}
+
 	// Return any error that occurred during command execution
 	if err != nil {
 		return fmt.Errorf("error running %s: %v", command, err)
 	}
+	if inPlace {
+		return nil
+	}
+	if goMod != nil {
+		if err := goMod.restore(); err != nil {
+			return err
+		}
+	}
+	return syncFromDir(fsys, root, copied)
+}
 
-	return nil
+// SDKVersion returns the version of the SDK required in go.mod. An
+// empty string is returned if the SDK isn't required.
+func SDKVersion(fsys FS) (string, error) {
+	data, err := fsys.ReadFile("go.mod")
+	if err != nil {
+		return "", err
+	}
+	f, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed parsing go.mod: %w", err)
+	}
+	for _, r := range f.Require {
+		if r.Mod.Path == sdkModule {
+			return r.Mod.Version, nil
+		}
+	}
+	return "", nil
 }
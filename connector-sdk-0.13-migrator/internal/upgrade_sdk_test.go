@@ -0,0 +1,65 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSDKVersion(t *testing.T) {
+	ctx := context.Background()
+	for _, requested := range []string{"v0.12.0", "v0.14.0", "v0.13", "0.13.1"} {
+		if _, err := ResolveSDKVersion(ctx, requested); err == nil {
+			t.Errorf("expected error for version %q", requested)
+		}
+	}
+	if got, err := ResolveSDKVersion(ctx, "v0.13.1"); err != nil || got != "v0.13.1" {
+		t.Errorf("expected requested version v0.13.1, got %q (%v)", got, err)
+	}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not available")
+	}
+	// The latest v0.13.x release is looked up in a file:// proxy.
+	proxy := t.TempDir()
+	dir := filepath.Join(proxy, sdkModule, "@v")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"list":         "v0.12.0\nv0.13.0\nv0.13.2\nv0.13.3-rc1\nv0.14.0\n",
+		"v0.13.2.info": `{"Version":"v0.13.2","Time":"2025-01-01T00:00:00Z"}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("GOPROXY", "file://"+filepath.ToSlash(proxy))
+	t.Setenv("GOMODCACHE", t.TempDir())
+	t.Setenv("GOFLAGS", "-modcacherw")
+
+	got, err := ResolveSDKVersion(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "v0.13.2" {
+		t.Errorf("expected v0.13.2, got %q", got)
+	}
+}
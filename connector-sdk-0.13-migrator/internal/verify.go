@@ -0,0 +1,142 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// verifyCommands are run in order to verify a migrated connector.
+var verifyCommands = [][]string{
+	{"go", "generate", "./..."},
+	{"go", "build", "./..."},
+	{"go", "vet", "./..."},
+	{"go", "test", "./..."},
+}
+
+// Verification is the result of verifying a migrated connector.
+type Verification struct {
+	Passed   bool            `json:"passed"`
+	Commands []CommandResult `json:"commands"`
+	// Problems are the errors reported by the commands, e.g. compiler
+	// errors.
+	Problems []Problem `json:"problems"`
+}
+
+// Problem is an error reported at a position in a file.
+type Problem struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	if p.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", p.File, p.Line, p.Column, p.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+}
+
+// problemRegex matches errors like "source/source.go:12:2: undefined: x",
+// as printed by the compiler, go vet and failing tests. "vet: " is printed
+// before vet errors that stop the analysis.
+var problemRegex = regexp.MustCompile(`^\s*(?:vet: )?(?:\./)?([^\s:]+\.go):(\d+)(?::(\d+))?: (.+)$`)
+
+// verify runs verifyCommands on the connector and stops at the first command
+// that fails. Files changed by the commands (e.g. by go generate) are
+// discarded.
+func verify(ctx context.Context, fsys FS) *Verification {
+	v := &Verification{
+		Commands: []CommandResult{},
+		Problems: []Problem{},
+	}
+	// Commands are recorded in the step report, collect them from there.
+	step := &StepReport{}
+	ctx = withStepReport(ctx, step)
+
+	fsys = NewOverlay(fsys)
+	v.Passed = true
+	for _, args := range verifyCommands {
+		command := strings.Join(args, " ")
+		fmt.Printf("Running %s\n", command)
+		err := runCommand(ctx, fsys, ".", args[0], args[1:]...)
+
+		// The command isn't recorded if it couldn't be started.
+		result := CommandResult{Dir: ".", Command: command, ExitCode: -1}
+		if len(step.Commands) > len(v.Commands) {
+			result = step.Commands[len(step.Commands)-1]
+		}
+		if err != nil && result.Error == "" {
+			result.Error = err.Error()
+		}
+		v.Commands = append(v.Commands, result)
+		if err != nil {
+			v.Passed = false
+			v.Problems = parseProblems(result.Stdout + "\n" + result.Stderr)
+			break
+		}
+	}
+	return v
+}
+
+// parseProblems returns the problems in the output of a go command. Duplicate
+// problems are only returned once.
+func parseProblems(output string) []Problem {
+	problems := []Problem{}
+	seen := make(map[Problem]bool)
+	for _, line := range strings.Split(output, "\n") {
+		match := problemRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		p := Problem{
+			File:    path.Clean(match[1]),
+			Message: strings.TrimSpace(match[4]),
+		}
+		p.Line, _ = strconv.Atoi(match[2])
+		p.Column, _ = strconv.Atoi(match[3])
+		if !seen[p] {
+			seen[p] = true
+			problems = append(problems, p)
+		}
+	}
+	return problems
+}
+
+// WriteSummary writes the outcome of the verification to w.
+func (v *Verification) WriteSummary(w io.Writer) error {
+	if v.Passed {
+		_, err := fmt.Fprintln(w, "Verification passed")
+		return err
+	}
+
+	failed := v.Commands[len(v.Commands)-1]
+	if _, err := fmt.Fprintf(w, "Verification failed: `%s` exited with code %d\n", failed.Command, failed.ExitCode); err != nil {
+		return err
+	}
+	for _, p := range v.Problems {
+		if _, err := fmt.Fprintf(w, "  %s\n", p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+// Copyright © 2025 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseProblems(t *testing.T) {
+	output := `# github.com/conduitio/conduit-connector-example/source
+source/source.go:52:37: s.Parameters undefined (type *Source has no field or method Parameters)
+./source/source.go:52:37: s.Parameters undefined (type *Source has no field or method Parameters)
+# github.com/conduitio/conduit-connector-example
+vet: connector.go:26:21: undefined: Specification
+--- FAIL: TestTeardown (0.00s)
+    source_test.go:14: expected error
+FAIL	github.com/conduitio/conduit-connector-example/source	0.011s
+`
+	want := []Problem{
+		{File: "source/source.go", Line: 52, Column: 37, Message: "s.Parameters undefined (type *Source has no field or method Parameters)"},
+		{File: "connector.go", Line: 26, Column: 21, Message: "undefined: Specification"},
+		{File: "source_test.go", Line: 14, Message: "expected error"},
+	}
+
+	got := parseProblems(output)
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected problems\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -15,10 +15,12 @@
 package internal
 
 import (
+	"bytes"
+	"context"
 	"embed"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
 )
 
 //go:embed release.yaml
@@ -26,21 +28,28 @@ var workflowFiles embed.FS
 
 type WorkflowRelease struct{}
 
-func (w WorkflowRelease) Migrate(workingDir string) error {
-	// Check for both possible file extensions
-	possiblePaths := []string{
-		filepath.Join(workingDir, ".github", "workflows", "release.yml"),
-		filepath.Join(workingDir, ".github", "workflows", "release.yaml"),
+func (w WorkflowRelease) Description() string {
+	return "Update the release workflow"
+}
+
+func (w WorkflowRelease) Check(fsys FS) (Status, error) {
+	workflowContent, err := workflowFiles.ReadFile("release.yaml")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read workflow file: %w", err)
 	}
 
-	// Check if either file exists
-	var existingPath string
-	for _, path := range possiblePaths {
-		if _, err := os.Stat(path); err == nil {
-			existingPath = path
-			break
-		}
+	existing, err := fsys.ReadFile(w.workflowPath(fsys))
+	if err == nil && bytes.Equal(existing, workflowContent) {
+		return StatusAlreadyApplied, nil
 	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, err
+	}
+	return StatusNeedsMigration, nil
+}
+
+func (w WorkflowRelease) Migrate(ctx context.Context, fsys FS) error {
+	existingPath := w.workflowPath(fsys)
 
 	// Read the embedded workflow file
 	workflowContent, err := workflowFiles.ReadFile("release.yaml")
@@ -48,16 +57,34 @@ func (w WorkflowRelease) Migrate(workingDir string) error {
 		return fmt.Errorf("failed to read workflow file: %w", err)
 	}
 
-	// Create the target directory if it doesn't exist
-	targetDir := filepath.Join(workingDir, ".github", "workflows")
-	if err := os.MkdirAll(targetDir, 0755); err != nil {
-		return fmt.Errorf("failed to create workflows directory: %w", err)
+	if exists, err := fileExists(fsys, existingPath); err != nil {
+		return err
+	} else if exists {
+		StepReportFromContext(ctx).Warnf("replaced %s, custom steps need to be added back manually", existingPath)
 	}
 
 	// Write the new file
-	if err := os.WriteFile(existingPath, workflowContent, 0644); err != nil {
+	if err := fsys.WriteFile(existingPath, workflowContent, 0644); err != nil {
 		return fmt.Errorf("failed to write workflow file: %w", err)
 	}
 
 	return nil
 }
+
+// workflowPath returns the path of the existing release workflow, or the
+// path where it should be created.
+func (w WorkflowRelease) workflowPath(fsys FS) string {
+	// Check for both possible file extensions
+	possiblePaths := []string{
+		".github/workflows/release.yml",
+		".github/workflows/release.yaml",
+	}
+
+	// Check if either file exists, create the first one otherwise
+	for _, path := range possiblePaths {
+		if _, err := fsys.Stat(path); err == nil {
+			return path
+		}
+	}
+	return possiblePaths[0]
+}
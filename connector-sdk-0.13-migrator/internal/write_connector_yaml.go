@@ -15,13 +15,11 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
-	"log"
-	"os"
-	"path/filepath"
 
 	"github.com/conduitio/yaml/v3"
 )
@@ -48,9 +46,32 @@ type YAMLSpecification struct {
 type WriteConnectorYaml struct {
 }
 
-func (w WriteConnectorYaml) Migrate(workingDir string) error {
+func (w WriteConnectorYaml) Description() string {
+	return "Write connector.yaml based on spec.go"
+}
+
+func (w WriteConnectorYaml) Check(fsys FS) (Status, error) {
+	yamlExists, err := fileExists(fsys, "connector.yaml")
+	if err != nil {
+		return 0, err
+	}
+	if yamlExists {
+		return StatusAlreadyApplied, nil
+	}
+
+	specExists, err := fileExists(fsys, "spec.go")
+	if err != nil {
+		return 0, err
+	}
+	if !specExists {
+		return StatusNotApplicable, nil
+	}
+	return StatusNeedsMigration, nil
+}
+
+func (w WriteConnectorYaml) Migrate(ctx context.Context, fsys FS) error {
 	// Extract specification fields
-	spec, err := w.extractSpecificationFields(filepath.Join(workingDir, "spec.go"))
+	spec, err := w.extractSpecificationFields(fsys, "spec.go")
 	if err != nil {
 		return fmt.Errorf("extract specification fields: %w", err)
 	}
@@ -58,19 +79,19 @@ func (w WriteConnectorYaml) Migrate(workingDir string) error {
 	// Convert to YAML structure
 	yamlSpec, err := w.convertToYAML(spec)
 	if err != nil {
-		log.Fatalf("Error converting to YAML: %v", err)
+		return fmt.Errorf("error converting to YAML: %w", err)
 	}
 
 	// Marshal to YAML
 	yamlData, err := yaml.Marshal(yamlSpec)
 	if err != nil {
-		log.Fatalf("Error marshaling YAML: %v", err)
+		return fmt.Errorf("error marshaling YAML: %w", err)
 	}
 
 	// Write to file
-	err = os.WriteFile(filepath.Join(workingDir, "connector.yaml"), yamlData, 0644)
+	err = fsys.WriteFile("connector.yaml", yamlData, 0644)
 	if err != nil {
-		log.Fatalf("Error writing YAML file: %v", err)
+		return fmt.Errorf("error writing YAML file: %w", err)
 	}
 
 	return nil
@@ -90,12 +111,17 @@ func (w WriteConnectorYaml) convertToYAML(spec *SpecificationInfo) (*YAMLSpecifi
 	return yamlSpec, nil
 }
 
-func (w WriteConnectorYaml) extractSpecificationFields(filename string) (*SpecificationInfo, error) {
+func (w WriteConnectorYaml) extractSpecificationFields(fsys FS, filename string) (*SpecificationInfo, error) {
+	src, err := fsys.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
 	// Create a new file set
 	fset := token.NewFileSet()
 
 	// Parse the source file
-	file, err := parser.ParseFile(fset, filename, nil, parser.AllErrors)
+	file, err := parser.ParseFile(fset, filename, src, parser.AllErrors)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing file: %v", err)
 	}
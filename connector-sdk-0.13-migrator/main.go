@@ -1,60 +1,323 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
-	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/conduitio/tools/connector-sdk-0.13-migrator/internal"
 )
 
-var allMigrators = []internal.Migrator{
-	internal.ToolsGo{},
-	internal.UpgradeSDK{},
-	internal.ConnectorGoMigrator{},
-	internal.UpdateSourceGo{},
-	internal.UpdateDestinationGo{},
-	internal.WriteConnectorYaml{},
-	internal.DeleteParamGen{},
-	internal.DeleteSpecGo{},
-	internal.WorkflowRelease{},
-	internal.GoReleaserMigrator{},
-	internal.MakefileMigrator{},
-	internal.ScriptsMigrator{},
-}
+var registry = internal.DefaultRegistry()
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "batch" {
+		batch(os.Args[2:])
+		return
+	}
+	migrate(os.Args[1:])
+}
+
+// options are the flags shared by the migrate and batch commands.
+type options struct {
+	dryRun      *bool
+	keepPartial *bool
+	useGit      *bool
+	only        *string
+	skip        *string
+	from        *string
+	reportPath  *string
+	verify      *bool
+	recipes     *string
+	interactive *bool
+	resume      *bool
+	sdkVersion  *string
+	enable      *string
+}
+
+func newFlagSet(name, usage string) (*flag.FlagSet, options) {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	opts := options{
+		dryRun:      flags.Bool("dry-run", false, "print a diff of all changes instead of writing them"),
+		keepPartial: flags.Bool("keep-partial", false, "keep the changes of successful migrators when a later one fails"),
+		useGit:      flags.Bool("git", false, "commit the changes of every migrator to the "+internal.MigrationBranch+" branch"),
+		only:        flags.String("only", "", "comma-separated list of migrators to run"),
+		skip:        flags.String("skip", "", "comma-separated list of migrators to skip"),
+		from:        flags.String("from", "", "start the migration at the given migrator"),
+		enable:      flags.String("enable", "", "comma-separated list of optional migrators to run"),
+		reportPath:  flags.String("report", "", "write a JSON report of the migration to the given file"),
+		verify:      flags.Bool("verify", false, "run go generate, build, vet and test after the migration"),
+		recipes:     flags.String("recipes", "", "directory with additional YAML recipes, which run after the other migrators"),
+		interactive: flags.Bool("interactive", false, "ask before applying each change"),
+		resume:      flags.Bool("resume", false, "continue an interrupted migration at the first migrator that didn't complete"),
+		sdkVersion:  flags.String("sdk-version", "", "SDK version to upgrade to (default: latest v0.13.x release from GOPROXY)"),
+	}
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s\n\nFlags:\n", os.Args[0], usage)
+		flags.PrintDefaults()
+	}
+	return flags, opts
+}
+
+// loadRecipes registers the recipes from the directory passed with --recipes.
+func (o options) loadRecipes() {
+	if *o.recipes == "" {
+		return
+	}
+	recipes, err := internal.LoadRecipes(*o.recipes)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, r := range recipes {
+		if err := registry.Append(r); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// resolveSDKVersion returns the SDK version connectors are upgraded to. The
+// latest release is only looked up if resolve is set, otherwise just the
+// version passed with --sdk-version is validated. Without one, the runner
+// looks up the latest release when the SDK is upgraded, so that migrations
+// that don't upgrade it work offline.
+func (o options) resolveSDKVersion(resolve bool) string {
+	if *o.sdkVersion == "" && !resolve {
+		return ""
+	}
+	version, err := internal.ResolveSDKVersion(context.Background(), *o.sdkVersion)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return version
+}
+
+// selection returns the selected migrators of the planned migration sets.
+// Names are matched against the migrators of all sets. The program exits if
+// a name doesn't match any migrator.
+func (o options) selection(plan []internal.MigrationSet, extra ...string) []internal.Migrator {
+	selection := internal.Selection{
+		Only:   append(splitList(*o.only), extra...),
+		Skip:   splitList(*o.skip),
+		From:   *o.from,
+		Enable: splitList(*o.enable),
+	}
+	selected, err := selection.Select(registry.Migrators())
+	if err != nil {
+		log.Fatalf("%v\nUse --list to see all migrators.", err)
+	}
+	if plan == nil {
+		return selected
+	}
+
+	var migrators []internal.Migrator
+	for _, set := range plan {
+		for _, m := range set.Migrators {
+			if slices.Contains(selected, m) {
+				migrators = append(migrators, m)
+			}
+		}
+	}
+	if len(migrators) == 0 {
+		log.Fatal("no migrators selected")
+	}
+	return migrators
+}
+
+// errNotConnector is returned by plan if the directory isn't a connector.
+var errNotConnector = errors.New("not a connector")
+
+// plan returns the migration sets needed to upgrade the connector in dir.
+func plan(dir string) ([]internal.MigrationSet, error) {
+	version, err := internal.SDKVersion(internal.NewOSFS(dir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s has no go.mod", errNotConnector, dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SDK version: %w", err)
+	}
+	if version == "" {
+		return nil, fmt.Errorf("%w: %s doesn't require the connector SDK", errNotConnector, dir)
+	}
+	sets, err := registry.Plan(version)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := make([]string, len(sets))
+	for i, set := range sets {
+		steps[i] = set.String()
+	}
+	fmt.Printf("SDK version %s, migrating %s\n", version, strings.Join(steps, ", "))
+	return sets, nil
+}
+
+// migrate migrates a single connector.
+func migrate(args []string) {
+	flags, opts := newFlagSet("migrate", "[flags] [path/to/connector] [migrator]\n       "+os.Args[0]+" batch [flags] <path/to/connector|glob>...")
+	list := flags.Bool("list", false, "list all migrators and exit")
+	_ = flags.Parse(args)
+	opts.loadRecipes()
+
+	if *list {
+		listMigrators()
+		return
+	}
+
 	// Working directory can be passed as an argument or use current directory
 	workingDir := "."
-	if len(os.Args) > 1 {
-		workingDir = os.Args[1]
+	if flags.NArg() > 0 {
+		workingDir = flags.Arg(0)
 	}
-	migrator := ""
-	if len(os.Args) > 2 {
-		migrator = os.Args[2]
+	// A single migrator can also be passed as the second argument.
+	var extra []string
+	if flags.NArg() > 1 {
+		extra = append(extra, flags.Arg(1))
 	}
 
-	var migrators []internal.Migrator
-	if migrator == "" {
-		migrators = allMigrators
-	} else {
-		for _, m := range allMigrators {
-			if reflect.TypeOf(m).Name() == migrator {
-				migrators = []internal.Migrator{m}
-				break
+	sets, err := plan(workingDir)
+	if err != nil {
+		// The report is written anyway, so that a batch counts directories
+		// that aren't connectors as skipped.
+		if *opts.reportPath != "" {
+			report := &internal.Report{Connector: workingDir, DryRun: *opts.dryRun, Migrators: []*internal.StepReport{}}
+			if errors.Is(err, errNotConnector) {
+				report.Skipped = err.Error()
+			} else {
+				report.Error = err.Error()
+			}
+			if reportErr := report.WriteFile(*opts.reportPath); reportErr != nil {
+				err = errors.Join(err, reportErr)
 			}
 		}
+		log.Fatal(err)
+	}
+
+	runner := internal.Runner{
+		Migrators:   opts.selection(sets, extra...),
+		DryRun:      *opts.dryRun,
+		KeepPartial: *opts.keepPartial,
+		Git:         *opts.useGit,
+		Verify:      *opts.verify,
+		Resume:      *opts.resume,
+		SDKVersion:  opts.resolveSDKVersion(false),
+	}
+	if *opts.interactive {
+		runner.Review = internal.NewReview(os.Stdin, os.Stdout)
+	}
+	report, err := runner.Run(context.Background(), workingDir)
+	if *opts.reportPath != "" {
+		if reportErr := report.WriteFile(*opts.reportPath); reportErr != nil {
+			err = errors.Join(err, reportErr)
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if report.Verification != nil && !report.Verification.Passed {
+		os.Exit(1)
+	}
+}
+
+// batch migrates many connectors in parallel.
+func batch(args []string) {
+	flags, opts := newFlagSet("batch", "batch [flags] <path/to/connector|glob>...")
+	jobs := flags.Int("jobs", runtime.NumCPU(), "number of connectors migrated in parallel")
+	_ = flags.Parse(args)
+	opts.loadRecipes()
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *opts.interactive {
+		log.Fatal("connectors can't be migrated interactively in a batch")
+	}
+	// Fail early on unknown migrators, instead of in every connector.
+	selected := opts.selection(nil)
+
+	dirs, err := internal.ExpandDirs(flags.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+	// The version is resolved once, so all connectors use the same one. It's
+	// only needed if the SDK is upgraded.
+	upgrade := slices.ContainsFunc(selected, func(m internal.Migrator) bool {
+		return internal.MigratorName(m) == internal.MigratorName(internal.UpgradeSDK{})
+	})
+	sdkVersion := opts.resolveSDKVersion(upgrade)
+	command, err := os.Executable()
+	if err != nil {
+		log.Fatalf("failed to locate migrator executable: %v", err)
+	}
+
+	// Every connector is migrated with the same flags, except for the
+	// report, which is collected into the batch report.
+	var childArgs []string
+	if sdkVersion != "" {
+		childArgs = append(childArgs, "--sdk-version="+sdkVersion)
+	}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name != "jobs" && f.Name != "report" && f.Name != "sdk-version" {
+			childArgs = append(childArgs, "--"+f.Name+"="+f.Value.String())
+		}
+	})
+
+	b := internal.Batch{
+		Command: command,
+		Args:    childArgs,
+		Jobs:    *jobs,
+	}
+	results, err := b.Run(context.Background(), dirs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := internal.WriteBatchSummary(os.Stdout, results); err != nil {
+		log.Fatal(err)
 	}
+	if *opts.reportPath != "" {
+		if err := internal.WriteBatchReport(*opts.reportPath, results); err != nil {
+			log.Fatal(err)
+		}
+	}
+	for _, r := range results {
+		if r.Status == internal.BatchFailed {
+			os.Exit(1)
+		}
+	}
+}
 
-	fmt.Printf("Migrating %v\n", workingDir)
+// listMigrators prints all migration sets and their migrators in the order
+// they run.
+func listMigrators() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, set := range registry.Sets() {
+		fmt.Fprintf(w, "%s:\n", set)
+		for _, m := range set.Migrators {
+			description := m.Description()
+			if internal.IsOptional(m) {
+				description += " (optional, use --enable)"
+			}
+			fmt.Fprintf(w, "  %s\t%s\n", internal.MigratorName(m), description)
+		}
+	}
+	w.Flush()
+}
 
-	for _, m := range migrators {
-		fmt.Printf("Running %T\n\n", m)
-		err := m.Migrate(workingDir)
-		if err != nil {
-			log.Fatalf("%T failed: %v", m, err)
+// splitList splits a comma-separated list, ignoring empty elements.
+func splitList(s string) []string {
+	var list []string
+	for _, elem := range strings.Split(s, ",") {
+		if elem = strings.TrimSpace(elem); elem != "" {
+			list = append(list, elem)
 		}
-		fmt.Printf("\nDone with %T\n-----------\n", m)
 	}
+	return list
 }